@@ -0,0 +1,278 @@
+package sysocr
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	altoNamespace      = "http://www.loc.gov/standards/alto/ns-v4#"
+	altoSchemaLocation = altoNamespace + " http://www.loc.gov/alto/v4/alto-4-2.xsd"
+	xsiNamespace       = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// altoDocument 是写出 ALTO v4 时使用的文档结构。
+type altoDocument struct {
+	XMLName        xml.Name        `xml:"alto"`
+	Xmlns          string          `xml:"xmlns,attr"`
+	XmlnsXsi       string          `xml:"xmlns:xsi,attr"`
+	SchemaLocation string          `xml:"xsi:schemaLocation,attr"`
+	Description    altoDescription `xml:"Description"`
+	Page           altoPage        `xml:"Layout>Page"`
+}
+
+type altoDescription struct {
+	MeasurementUnit string         `xml:"MeasurementUnit"`
+	Processing      altoProcessing `xml:"OCRProcessing"`
+}
+
+type altoProcessing struct {
+	ID       string `xml:"ID,attr"`
+	Software string `xml:"ocrProcessingStep>processingSoftware>softwareName"`
+}
+
+type altoPage struct {
+	ID            string         `xml:"ID,attr"`
+	PhysicalImgNr int            `xml:"PHYSICAL_IMG_NR,attr"`
+	Width         int            `xml:"WIDTH,attr"`
+	Height        int            `xml:"HEIGHT,attr"`
+	PrintSpace    altoPrintSpace `xml:"PrintSpace"`
+}
+
+type altoPrintSpace struct {
+	altoBox
+	Blocks []altoTextBlock `xml:"TextBlock"`
+}
+
+// altoBox 是 ALTO 元素共用的位置属性。
+type altoBox struct {
+	HPos   int `xml:"HPOS,attr"`
+	VPos   int `xml:"VPOS,attr"`
+	Width  int `xml:"WIDTH,attr"`
+	Height int `xml:"HEIGHT,attr"`
+}
+
+type altoTextBlock struct {
+	ID string `xml:"ID,attr"`
+	altoBox
+	Lines []altoTextLine `xml:"TextLine"`
+}
+
+type altoTextLine struct {
+	ID string `xml:"ID,attr"`
+	altoBox
+	Items []any // altoString 与 altoSpace 交替出现
+}
+
+type altoString struct {
+	XMLName xml.Name `xml:"String"`
+	ID      string   `xml:"ID,attr"`
+	altoBox
+	Content string `xml:"CONTENT,attr"`
+	WC      string `xml:"WC,attr,omitempty"`
+}
+
+type altoSpace struct {
+	XMLName xml.Name `xml:"SP"`
+}
+
+// altoLineIn 是读取 ALTO 时使用的行结构，坐标可能是小数。
+type altoLineIn struct {
+	HPos    float64 `xml:"HPOS,attr"`
+	VPos    float64 `xml:"VPOS,attr"`
+	Width   float64 `xml:"WIDTH,attr"`
+	Height  float64 `xml:"HEIGHT,attr"`
+	Strings []struct {
+		HPos    float64 `xml:"HPOS,attr"`
+		VPos    float64 `xml:"VPOS,attr"`
+		Width   float64 `xml:"WIDTH,attr"`
+		Height  float64 `xml:"HEIGHT,attr"`
+		Content string  `xml:"CONTENT,attr"`
+		WC      string  `xml:"WC,attr"`
+	} `xml:"String"`
+}
+
+// WriteALTO 以 ALTO v4 格式写出识别结果，坐标单位为像素（MeasurementUnit 为 pixel）。
+//
+// 每个段落写为一个 TextBlock，每行写为 TextLine，单词写为 String 并以 SP 分隔；
+// 引擎不提供单词时整行写为一个 String。置信度已知时写入 String 的 WC 属性。
+// imageWidth、imageHeight 为 0 时使用 Result.ImageWidth、Result.ImageHeight。
+func (r *Result) WriteALTO(w io.Writer, imageWidth, imageHeight int) error {
+	imageWidth, imageHeight, err := r.outputSize(imageWidth, imageHeight)
+	if err != nil {
+		return err
+	}
+	box := func(b BoundingBox) altoBox {
+		p := b.Pixels(imageWidth, imageHeight)
+		return altoBox{HPos: p.Min.X, VPos: p.Min.Y, Width: p.Dx(), Height: p.Dy()}
+	}
+
+	doc := altoDocument{
+		Xmlns:          altoNamespace,
+		XmlnsXsi:       xsiNamespace,
+		SchemaLocation: altoSchemaLocation,
+		Description: altoDescription{
+			MeasurementUnit: "pixel",
+			Processing:      altoProcessing{ID: "OCR_0", Software: "sysocr"},
+		},
+		Page: altoPage{
+			ID:            "page_1",
+			PhysicalImgNr: 1,
+			Width:         imageWidth,
+			Height:        imageHeight,
+		},
+	}
+	space := &doc.Page.PrintSpace
+	space.altoBox = altoBox{Width: imageWidth, Height: imageHeight}
+
+	var blockID, lineID, wordID int
+	for _, region := range r.layout(imageWidth, imageHeight).Regions {
+		for _, para := range region.Paragraphs {
+			blockID++
+			block := altoTextBlock{ID: "block_" + strconv.Itoa(blockID), altoBox: box(para.BoundingBox)}
+			for _, l := range para.Lines {
+				lineID++
+				line := altoTextLine{ID: "line_" + strconv.Itoa(lineID), altoBox: box(l.BoundingBox)}
+				words := l.Words
+				if len(words) == 0 {
+					// 没有单词信息时整行写为一个与行边界框相同的 String，而不是写出近似的单词，读取时据此还原
+					words = []Word{{Text: l.Text, BoundingBox: l.BoundingBox, Confidence: l.Confidence}}
+				}
+				for i, word := range words {
+					wordID++
+					if i > 0 {
+						line.Items = append(line.Items, altoSpace{})
+					}
+					s := altoString{ID: "string_" + strconv.Itoa(wordID), altoBox: box(word.BoundingBox), Content: word.Text}
+					if word.Confidence >= 0 {
+						s.WC = formatConfidence(word.Confidence)
+					}
+					line.Items = append(line.Items, s)
+				}
+				block.Lines = append(block.Lines, line)
+			}
+			space.Blocks = append(space.Blocks, block)
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", " ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// ReadALTO 从 ALTO 文档（v2 至 v4）读取识别结果，只读取第一页。
+//
+// 文档中任意位置（包括 ComposedBlock 中）的 TextLine 都会按出现顺序成为一个 TextBlock，
+// String 成为 Word，行的置信度为各单词 WC 的平均值；只有一个与行的边界框相同的 String 时
+// 视为没有单词信息（与 WriteALTO 对应），TextBlock.Words 为空。坐标按 Page 的 WIDTH、HEIGHT 归一化，
+// 因此不要求 MeasurementUnit 为 pixel；只有单位为 pixel 时才填充 ImageWidth、ImageHeight。
+func ReadALTO(r io.Reader) (*Result, error) {
+	dec := xml.NewDecoder(r)
+	result := &Result{}
+	unit := "mm10" // ALTO 规定的默认单位
+	var pageWidth, pageHeight float64
+	pages := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("sysocr: decode ALTO: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "MeasurementUnit":
+			if err := dec.DecodeElement(&unit, &start); err != nil {
+				return nil, fmt.Errorf("sysocr: decode ALTO: %w", err)
+			}
+			unit = strings.TrimSpace(unit)
+		case "Page":
+			if pages++; pages > 1 {
+				return finishALTO(result, unit, pageWidth, pageHeight)
+			}
+			for _, a := range start.Attr {
+				switch a.Name.Local {
+				case "WIDTH":
+					pageWidth, _ = strconv.ParseFloat(a.Value, 64)
+				case "HEIGHT":
+					pageHeight, _ = strconv.ParseFloat(a.Value, 64)
+				}
+			}
+			if pageWidth <= 0 || pageHeight <= 0 {
+				return nil, errors.New("sysocr: decode ALTO: page has no WIDTH/HEIGHT")
+			}
+		case "TextLine":
+			if pages == 0 {
+				return nil, errors.New("sysocr: decode ALTO: TextLine outside of Page")
+			}
+			var line altoLineIn
+			if err := dec.DecodeElement(&line, &start); err != nil {
+				return nil, fmt.Errorf("sysocr: decode ALTO: %w", err)
+			}
+			result.Blocks = append(result.Blocks, altoBlock(line, pageWidth, pageHeight))
+		}
+	}
+	return finishALTO(result, unit, pageWidth, pageHeight)
+}
+
+// altoBlock 将 ALTO 的 TextLine 转换为 TextBlock。
+func altoBlock(line altoLineIn, pageWidth, pageHeight float64) TextBlock {
+	block := TextBlock{
+		BoundingBox: normalizeBox(line.HPos, line.VPos, line.Width, line.Height, pageWidth, pageHeight),
+		Confidence:  UnknownConfidence,
+	}
+	texts := make([]string, 0, len(line.Strings))
+	sum, known := 0.0, true
+	for _, s := range line.Strings {
+		word := Word{
+			Text:        s.Content,
+			BoundingBox: normalizeBox(s.HPos, s.VPos, s.Width, s.Height, pageWidth, pageHeight),
+			Confidence:  UnknownConfidence,
+		}
+		if c, err := strconv.ParseFloat(s.WC, 64); err == nil {
+			word.Confidence = c
+			sum += c
+		} else {
+			known = false
+		}
+		block.Words = append(block.Words, word)
+		texts = append(texts, s.Content)
+	}
+	block.Text = strings.Join(texts, " ")
+	if known && len(line.Strings) > 0 {
+		block.Confidence = sum / float64(len(line.Strings))
+	}
+	if len(line.Strings) == 1 {
+		s := line.Strings[0]
+		if s.HPos == line.HPos && s.VPos == line.VPos && s.Width == line.Width && s.Height == line.Height {
+			block.Words = nil
+		}
+	}
+	return block
+}
+
+func finishALTO(result *Result, unit string, pageWidth, pageHeight float64) (*Result, error) {
+	if pageWidth <= 0 || pageHeight <= 0 {
+		return nil, errors.New("sysocr: decode ALTO: no Page element")
+	}
+	if unit == "pixel" {
+		result.ImageWidth, result.ImageHeight = int(pageWidth), int(pageHeight)
+	}
+	result.finish()
+	return result, nil
+}
@@ -0,0 +1,141 @@
+package sysocr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// roundTripResult 返回像素对齐的识别结果，包含有单词和没有单词信息的行。
+func roundTripResult() *Result {
+	blocks := []TextBlock{
+		{
+			Text: "Hello world", BoundingBox: BoundingBox{0.1, 0.1, 0.5, 0.04}, Confidence: 0.93,
+			Words: []Word{
+				{Text: "Hello", BoundingBox: BoundingBox{0.1, 0.1, 0.2, 0.04}, Confidence: 0.96},
+				{Text: "world", BoundingBox: BoundingBox{0.35, 0.1, 0.25, 0.04}, Confidence: 0.9},
+			},
+		},
+		{
+			Text: "single", BoundingBox: BoundingBox{0.1, 0.16, 0.5, 0.04}, Confidence: 0.8,
+			Words: []Word{{Text: "single", BoundingBox: BoundingBox{0.1, 0.16, 0.3, 0.04}, Confidence: 0.8}},
+		},
+		// 引擎不提供单词信息的行
+		{Text: "no word data", BoundingBox: BoundingBox{0.1, 0.22, 0.5, 0.04}, Confidence: 0.75},
+		{Text: "unknown", BoundingBox: BoundingBox{0.1, 0.28, 0.5, 0.04}, Confidence: UnknownConfidence},
+	}
+	for i := range blocks {
+		blocks[i].Quad = blocks[i].BoundingBox.Quad()
+	}
+	return &Result{Blocks: blocks, ImageWidth: 1000, ImageHeight: 500}
+}
+
+// compareBlocks 比较往返转换前后的文本块。
+func compareBlocks(t *testing.T, got, want []TextBlock) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d blocks, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Text != w.Text || !near(g.Confidence, w.Confidence) || !boxNear(g.BoundingBox, w.BoundingBox) {
+			t.Errorf("block %d = %q %v %+v, want %q %v %+v",
+				i, g.Text, g.Confidence, g.BoundingBox, w.Text, w.Confidence, w.BoundingBox)
+		}
+		for j := range w.Quad {
+			if !near(g.Quad[j].X, w.Quad[j].X) || !near(g.Quad[j].Y, w.Quad[j].Y) {
+				t.Errorf("block %d quad = %v, want %v", i, g.Quad, w.Quad)
+				break
+			}
+		}
+		if len(g.Words) != len(w.Words) {
+			t.Errorf("block %d has %d words, want %d: %+v", i, len(g.Words), len(w.Words), g.Words)
+			continue
+		}
+		for j, ww := range w.Words {
+			gw := g.Words[j]
+			if gw.Text != ww.Text || !near(gw.Confidence, ww.Confidence) || !boxNear(gw.BoundingBox, ww.BoundingBox) {
+				t.Errorf("block %d word %d = %+v, want %+v", i, j, gw, ww)
+			}
+		}
+	}
+}
+
+func TestALTORoundTrip(t *testing.T) {
+	want := roundTripResult()
+	var buf bytes.Buffer
+	if err := want.WriteALTO(&buf, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), `CONTENT="word"`) {
+		t.Fatalf("lines without word data were split into approximated words:\n%s", buf.String())
+	}
+	got, err := ReadALTO(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareBlocks(t, got.Blocks, want.Blocks)
+	if got.ImageWidth != 1000 || got.ImageHeight != 500 || got.TextAngle != 0 {
+		t.Errorf("page = %dx%d angle %v", got.ImageWidth, got.ImageHeight, got.TextAngle)
+	}
+	if got.Text != joinText(want.Blocks) || got.Page == nil || got.SchemaVersion != SchemaVersion1 {
+		t.Errorf("decoded result was not finished: %+v", got)
+	}
+}
+
+func TestReadALTO(t *testing.T) {
+	// 其他工具输出的 ALTO v2：单位为 mm10，坐标为小数，TextLine 嵌套在 ComposedBlock 中，只读取第一页
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<alto xmlns="http://www.loc.gov/standards/alto/ns-v2#">
+ <Description><MeasurementUnit>mm10</MeasurementUnit></Description>
+ <Layout>
+  <Page WIDTH="2000" HEIGHT="1000">
+   <PrintSpace>
+    <ComposedBlock>
+     <TextBlock>
+      <TextLine HPOS="100.5" VPOS="100" WIDTH="800" HEIGHT="50">
+       <String HPOS="100.5" VPOS="100" WIDTH="300" HEIGHT="50" CONTENT="first" WC="0.5"/>
+       <SP/>
+       <String HPOS="500" VPOS="100" WIDTH="400.5" HEIGHT="50" CONTENT="line"/>
+      </TextLine>
+     </TextBlock>
+    </ComposedBlock>
+   </PrintSpace>
+  </Page>
+  <Page WIDTH="2000" HEIGHT="1000">
+   <PrintSpace><TextBlock><TextLine HPOS="0" VPOS="0" WIDTH="10" HEIGHT="10">
+    <String HPOS="0" VPOS="0" WIDTH="10" HEIGHT="10" CONTENT="second page"/>
+   </TextLine></TextBlock></PrintSpace>
+  </Page>
+ </Layout>
+</alto>`
+	got, err := ReadALTO(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TextBlock{{
+		Text: "first line", BoundingBox: BoundingBox{0.05025, 0.1, 0.4, 0.05}, Confidence: UnknownConfidence,
+		Quad: BoundingBox{0.05025, 0.1, 0.4, 0.05}.Quad(),
+		Words: []Word{
+			{Text: "first", BoundingBox: BoundingBox{0.05025, 0.1, 0.15, 0.05}, Confidence: 0.5},
+			{Text: "line", BoundingBox: BoundingBox{0.25, 0.1, 0.20025, 0.05}, Confidence: UnknownConfidence},
+		},
+	}}
+	compareBlocks(t, got.Blocks, want)
+	if got.ImageWidth != 0 || got.ImageHeight != 0 {
+		t.Errorf("image size = %dx%d, want 0 for non-pixel units", got.ImageWidth, got.ImageHeight)
+	}
+}
+
+func TestReadALTOInvalid(t *testing.T) {
+	for name, doc := range map[string]string{
+		"no page":      `<alto><Layout></Layout></alto>`,
+		"no page size": `<alto><Layout><Page></Page></Layout></alto>`,
+		"line outside": `<alto><TextLine/></alto>`,
+		"not xml":      `<alto><Layout>`,
+	} {
+		if _, err := ReadALTO(strings.NewReader(doc)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
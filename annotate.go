@@ -0,0 +1,231 @@
+package sysocr
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+)
+
+// AnnotateOptions 配置 AnnotatePNG 和 AnnotateSVG。
+type AnnotateOptions struct {
+	Labels    bool // 在每个文本块左上角标注其在 Result.Blocks 中的下标
+	LineWidth int  // 文本块边框的线宽（像素），0 表示根据图片尺寸自动选择
+}
+
+// 按置信度区分的颜色：≥0.8 绿色，≥0.5 黄色，<0.5 红色，未知为蓝色。
+var (
+	annotateHigh    = color.NRGBA{0, 200, 0, 255}
+	annotateMedium  = color.NRGBA{230, 190, 0, 255}
+	annotateLow     = color.NRGBA{220, 0, 0, 255}
+	annotateUnknown = color.NRGBA{0, 110, 255, 255}
+)
+
+// digitFont 是 3×5 的点阵数字字体，每行低 3 位从左到右表示像素。
+var digitFont = [10][5]uint8{
+	{7, 5, 5, 5, 7}, {2, 6, 2, 2, 7}, {7, 1, 7, 4, 7}, {7, 1, 7, 1, 7}, {5, 5, 7, 1, 1},
+	{7, 4, 7, 1, 7}, {7, 4, 7, 5, 7}, {7, 1, 1, 1, 1}, {7, 5, 7, 5, 7}, {7, 5, 7, 1, 7},
+}
+
+// confidenceColor 返回置信度对应的标注颜色。
+func confidenceColor(c float64) color.NRGBA {
+	switch {
+	case c < 0:
+		return annotateUnknown
+	case c >= 0.8:
+		return annotateHigh
+	case c >= 0.5:
+		return annotateMedium
+	}
+	return annotateLow
+}
+
+// lineWidth 返回文本块边框的线宽。
+func (o AnnotateOptions) lineWidth(imageWidth, imageHeight int) int {
+	if o.LineWidth > 0 {
+		return o.LineWidth
+	}
+	return max(1, min(imageWidth, imageHeight)/400)
+}
+
+// AnnotatePNG 在 imageData 的副本上绘制识别结果并返回 PNG 数据，用于排查识别问题。
+//
+// 每个文本块的 BoundingBox 以按置信度着色的边框绘制；Quad 与边界框不同时（倾斜文本）额外绘制四边形，
+// 单词以较细的边框绘制。支持 PNG、JPEG 和 GIF 图片，Result.Orientation 不为 0 时先按 EXIF 方向校正。
+func (r *Result) AnnotatePNG(imageData []byte, opts AnnotateOptions) ([]byte, error) {
+	info, err := DetectImage(imageData)
+	if err != nil {
+		return nil, err
+	}
+	if r.Orientation != 0 {
+		imageData, info, _, err = normalizeOrientation(imageData, info)
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch info.Format {
+	case FormatPNG, FormatJPEG, FormatGIF:
+	default:
+		return nil, fmt.Errorf("%w: cannot annotate %s images", ErrUnsupportedFormat, info.Format)
+	}
+	src, _, err := image.Decode(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	b := src.Bounds()
+	canvas := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(canvas, canvas.Bounds(), src, b.Min, draw.Src)
+	w, h := b.Dx(), b.Dy()
+	lw := opts.lineWidth(w, h)
+	toPixel := func(p Point) image.Point {
+		return image.Pt(int(p.X*float64(w)), int(p.Y*float64(h)))
+	}
+
+	for _, block := range r.Blocks {
+		c := confidenceColor(block.Confidence)
+		for _, word := range block.Words {
+			drawPolygon(canvas, word.BoundingBox.Quad(), toPixel, confidenceColor(word.Confidence), max(1, lw/2))
+		}
+		drawPolygon(canvas, block.BoundingBox.Quad(), toPixel, c, lw)
+		if !block.Quad.IsZero() && block.Quad != block.BoundingBox.Quad() {
+			drawPolygon(canvas, block.Quad, toPixel, c, max(1, lw/2))
+		}
+	}
+	if opts.Labels {
+		scale := max(2, lw*2)
+		for i, block := range r.Blocks {
+			drawLabel(canvas, toPixel(Point{block.BoundingBox.X, block.BoundingBox.Y}), i,
+				confidenceColor(block.Confidence), scale)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := png.Encode(&out, canvas); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// drawPolygon 沿四边形的四条边画线。
+func drawPolygon(img *image.RGBA, q Quad, toPixel func(Point) image.Point, c color.NRGBA, width int) {
+	for i := range q {
+		drawLine(img, toPixel(q[i]), toPixel(q[(i+1)%len(q)]), c, width)
+	}
+}
+
+// drawLine 用 Bresenham 算法画一条宽为 width 的线段。
+func drawLine(img *image.RGBA, a, b image.Point, c color.NRGBA, width int) {
+	dx, dy := abs(b.X-a.X), -abs(b.Y-a.Y)
+	sx, sy := 1, 1
+	if a.X > b.X {
+		sx = -1
+	}
+	if a.Y > b.Y {
+		sy = -1
+	}
+	half := (width - 1) / 2
+	e := dx + dy
+	for {
+		draw.Draw(img, image.Rect(a.X-half, a.Y-half, a.X-half+width, a.Y-half+width), image.NewUniform(c), image.Point{}, draw.Src)
+		if a == b {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			a.X += sx
+		}
+		if e2 <= dx {
+			e += dx
+			a.Y += sy
+		}
+	}
+}
+
+// drawLabel 在 at 处以白底绘制数字 n，每个点阵像素放大为 scale×scale。
+func drawLabel(img *image.RGBA, at image.Point, n int, c color.NRGBA, scale int) {
+	digits := strconv.Itoa(n)
+	// 每个数字 3 列加 1 列间距，四周留 1 个点阵像素的边距
+	bg := image.Rect(0, 0, (len(digits)*4+1)*scale, 7*scale).Add(at)
+	draw.Draw(img, bg, image.White, image.Point{}, draw.Src)
+	for i, d := range digits {
+		glyph := digitFont[d-'0']
+		for row, bits := range glyph {
+			for col := 0; col < 3; col++ {
+				if bits&(4>>col) == 0 {
+					continue
+				}
+				x := at.X + (1+i*4+col)*scale
+				y := at.Y + (1+row)*scale
+				draw.Draw(img, image.Rect(x, y, x+scale, y+scale), image.NewUniform(c), image.Point{}, draw.Src)
+			}
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// AnnotateSVG 以 SVG 格式写出识别结果的透明叠加层，坐标单位为像素，可以叠放在网页中的原图上。
+// 颜色和绘制内容与 AnnotatePNG 相同，每个文本块带有显示文本和置信度的 title。
+// imageWidth、imageHeight 为 0 时使用 Result.ImageWidth、Result.ImageHeight。
+func (r *Result) AnnotateSVG(w io.Writer, imageWidth, imageHeight int, opts AnnotateOptions) error {
+	imageWidth, imageHeight, err := r.outputSize(imageWidth, imageHeight)
+	if err != nil {
+		return err
+	}
+	lw := opts.lineWidth(imageWidth, imageHeight)
+	fw, fh := float64(imageWidth), float64(imageHeight)
+	hex := func(c color.NRGBA) string { return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B) }
+	points := func(q Quad) string {
+		var b bytes.Buffer
+		for i, p := range q {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(pdfNumber(p.X*fw) + "," + pdfNumber(p.Y*fh))
+		}
+		return b.String()
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		imageWidth, imageHeight, imageWidth, imageHeight)
+	fmt.Fprintf(bw, " <g fill=\"none\" stroke-width=\"%d\">\n", lw)
+	for i, block := range r.Blocks {
+		c := hex(confidenceColor(block.Confidence))
+		title := strconv.Itoa(i) + ": " + block.Text
+		if block.Confidence >= 0 {
+			title += " (" + formatConfidence(block.Confidence) + ")"
+		}
+		fmt.Fprintf(bw, "  <g stroke=\"%s\">\n   <title>%s</title>\n", c, html.EscapeString(title))
+		for _, word := range block.Words {
+			fmt.Fprintf(bw, "   <polygon points=\"%s\" stroke=\"%s\" stroke-width=\"%d\"/>\n",
+				points(word.BoundingBox.Quad()), hex(confidenceColor(word.Confidence)), max(1, lw/2))
+		}
+		fmt.Fprintf(bw, "   <polygon points=\"%s\"/>\n", points(block.BoundingBox.Quad()))
+		if !block.Quad.IsZero() && block.Quad != block.BoundingBox.Quad() {
+			fmt.Fprintf(bw, "   <polygon points=\"%s\" stroke-width=\"%d\" stroke-dasharray=\"4 2\"/>\n",
+				points(block.Quad), max(1, lw/2))
+		}
+		if opts.Labels {
+			size := max(10, lw*8)
+			fmt.Fprintf(bw, "   <text x=\"%s\" y=\"%s\" font-family=\"monospace\" font-size=\"%d\" fill=\"%s\" stroke=\"none\" dominant-baseline=\"hanging\">%d</text>\n",
+				pdfNumber(block.BoundingBox.X*fw), pdfNumber(block.BoundingBox.Y*fh), size, c, i)
+		}
+		bw.WriteString("  </g>\n")
+	}
+	bw.WriteString(" </g>\n</svg>\n")
+	return bw.Flush()
+}
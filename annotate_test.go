@@ -0,0 +1,161 @@
+package sysocr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+)
+
+// annotateTestResult 返回 200×100 图片上置信度各不相同的文本块。
+func annotateTestResult() *Result {
+	return &Result{
+		Blocks: []TextBlock{
+			{
+				Text: "high", BoundingBox: BoundingBox{0.1, 0.1, 0.3, 0.2}, Confidence: 0.9,
+				Words: []Word{{Text: "high", BoundingBox: BoundingBox{0.15, 0.12, 0.2, 0.16}, Confidence: 0.3}},
+			},
+			{Text: "medium", BoundingBox: BoundingBox{0.5, 0.1, 0.3, 0.2}, Confidence: 0.6},
+			{Text: "low <&>", BoundingBox: BoundingBox{0.1, 0.5, 0.3, 0.2}, Confidence: 0.3},
+			{
+				Text: "unknown", BoundingBox: BoundingBox{0.5, 0.5, 0.4, 0.4}, Confidence: UnknownConfidence,
+				Quad: Quad{{0.5, 0.6}, {0.9, 0.5}, {0.9, 0.8}, {0.5, 0.9}},
+			},
+		},
+		ImageWidth:  200,
+		ImageHeight: 100,
+	}
+}
+
+// whitePNG 返回 w×h 的白色 PNG。
+func whitePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func annotate(t *testing.T, r *Result, data []byte, opts AnnotateOptions) image.Image {
+	t.Helper()
+	out, err := r.AnnotatePNG(data, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestAnnotatePNG(t *testing.T) {
+	img := annotate(t, annotateTestResult(), whitePNG(t, 200, 100), AnnotateOptions{LineWidth: 2})
+	if img.Bounds() != image.Rect(0, 0, 200, 100) {
+		t.Fatalf("bounds = %v", img.Bounds())
+	}
+	white := color.NRGBA{255, 255, 255, 255}
+	for _, tt := range []struct {
+		name string
+		x, y int
+		want color.NRGBA
+	}{
+		// 各文本块左边框的中点
+		{"high", 20, 20, annotateHigh},
+		{"medium", 100, 20, annotateMedium},
+		{"low", 20, 60, annotateLow},
+		{"unknown", 100, 70, annotateUnknown},
+		// 单词按自己的置信度着色
+		{"word", 30, 20, annotateLow},
+		// 倾斜文本的四边形：上边从 (100, 60) 到 (180, 50)
+		{"quad", 140, 55, annotateUnknown},
+		{"inside", 60, 20, white},
+		{"outside", 5, 5, white},
+	} {
+		got := color.NRGBAModel.Convert(img.At(tt.x, tt.y)).(color.NRGBA)
+		if got != tt.want {
+			t.Errorf("%s: pixel (%d, %d) = %v, want %v", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestAnnotatePNGLabels(t *testing.T) {
+	r := annotateTestResult()
+	data := whitePNG(t, 200, 100)
+	plain := annotate(t, r, data, AnnotateOptions{LineWidth: 2})
+	labeled := annotate(t, r, data, AnnotateOptions{LineWidth: 2, Labels: true})
+
+	// 点阵放大为 4×4，文本块 1 的标签 "1" 从 (100, 10) 开始：白色背景覆盖了边框，
+	// 数字 1 的第一行只有中间一列
+	at := func(img image.Image, x, y int) color.NRGBA {
+		return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+	}
+	if got := at(plain, 100, 12); got != annotateMedium {
+		t.Fatalf("border without labels = %v", got)
+	}
+	for _, tt := range []struct {
+		x, y int
+		want color.NRGBA
+	}{
+		{100, 12, color.NRGBA{255, 255, 255, 255}}, // 标签背景
+		{109, 15, annotateMedium},                  // 第 1 行第 2 列
+		{105, 15, color.NRGBA{255, 255, 255, 255}}, // 第 1 行第 1 列为空
+		{113, 31, annotateMedium},                  // 第 5 行第 3 列
+	} {
+		if got := at(labeled, tt.x, tt.y); got != tt.want {
+			t.Errorf("label pixel (%d, %d) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestAnnotatePNGOrientation(t *testing.T) {
+	// EXIF 方向为顺时针 90° 的 16×8 JPEG：结果基于 8×16 的显示方向
+	jpg := withExif(testJPEG(t), exifTIFF(binary.LittleEndian, 0x0112, 3, uint16(OrientationRotate90)))
+	r := &Result{Orientation: OrientationRotate90, Blocks: []TextBlock{{BoundingBox: BoundingBox{0, 0.5, 1, 0.5}, Confidence: 1}}}
+	img := annotate(t, r, jpg, AnnotateOptions{LineWidth: 1})
+	if img.Bounds() != image.Rect(0, 0, 8, 16) {
+		t.Fatalf("bounds = %v, want 8x16", img.Bounds())
+	}
+	if got := color.NRGBAModel.Convert(img.At(0, 12)).(color.NRGBA); got != annotateHigh {
+		t.Errorf("border pixel = %v, want %v", got, annotateHigh)
+	}
+}
+
+func TestAnnotatePNGUnsupported(t *testing.T) {
+	heif := testHEIF("heic", ispe(10, 10))
+	if _, err := annotateTestResult().AnnotatePNG(heif, AnnotateOptions{}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("HEIF: err = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestAnnotateSVG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := annotateTestResult().AnnotateSVG(&buf, 0, 0, AnnotateOptions{Labels: true}); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "annotate.svg", buf.Bytes())
+
+	// 输出是格式正确的 XML，标题中的特殊字符已转义
+	dec := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("invalid XML: %v", err)
+		}
+	}
+
+	if err := (&Result{}).AnnotateSVG(io.Discard, 0, 0, AnnotateOptions{}); !errors.Is(err, ErrUnknownImageSize) {
+		t.Errorf("unknown size: err = %v, want ErrUnknownImageSize", err)
+	}
+}
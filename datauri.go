@@ -0,0 +1,117 @@
+package sysocr
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrInvalidDataURI       = errors.New("sysocr: malformed data URI")
+	ErrInvalidBase64        = errors.New("sysocr: malformed base64 image data")
+	ErrUnsupportedMediaType = errors.New("sysocr: unsupported image media type")
+)
+
+// dataURIMediaTypes 是 data URI 中允许的图片类型。
+var dataURIMediaTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/jpg":  true,
+	"image/gif":  true,
+	"image/bmp":  true,
+	"image/tiff": true,
+	"image/webp": true,
+}
+
+// isDataURI 判断字符串是否为 data URI（scheme 不区分大小写）。
+func isDataURI(s string) bool {
+	return len(s) >= 5 && strings.EqualFold(s[:5], "data:")
+}
+
+// decodeDataURI 解码 data:[<mediatype>][;base64],<data> 形式的图片数据。
+func decodeDataURI(uri string) ([]byte, error) {
+	if !isDataURI(uri) {
+		return nil, ErrInvalidDataURI
+	}
+	header, payload, ok := strings.Cut(uri[5:], ",")
+	if !ok {
+		return nil, fmt.Errorf("%w: missing comma", ErrInvalidDataURI)
+	}
+
+	isBase64 := false
+	if h, found := strings.CutSuffix(header, ";base64"); found {
+		header, isBase64 = h, true
+	}
+	if header == "" {
+		// 按 RFC 2397，省略的媒体类型为 text/plain
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedMediaType, "text/plain")
+	}
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDataURI, err)
+	}
+	if !dataURIMediaTypes[mediaType] {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedMediaType, mediaType)
+	}
+
+	if isBase64 {
+		// base64 部分可能被百分号编码（如 %2B、%3D）
+		if strings.Contains(payload, "%") {
+			unescaped, err := url.PathUnescape(payload)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidDataURI, err)
+			}
+			payload = unescaped
+		}
+		return decodeBase64(payload)
+	}
+
+	data, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDataURI, err)
+	}
+	if data == "" {
+		return nil, fmt.Errorf("%w: empty payload", ErrInvalidDataURI)
+	}
+	return []byte(data), nil
+}
+
+// base64Encodings 按顺序尝试的编码方式，兼容标准和 URL 安全字母表，以及省略填充的写法。
+var base64Encodings = []*base64.Encoding{
+	base64.StdEncoding,
+	base64.RawStdEncoding,
+	base64.URLEncoding,
+	base64.RawURLEncoding,
+}
+
+// decodeBase64 解码 base64 图片数据，忽略其中的空白字符（如按 76 列换行的输出）。
+// 以 "data:" 开头的字符串按 data URI 处理。
+func decodeBase64(s string) ([]byte, error) {
+	if isDataURI(s) {
+		return decodeDataURI(s)
+	}
+
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n':
+			return -1
+		}
+		return r
+	}, s)
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty payload", ErrInvalidBase64)
+	}
+
+	var lastErr error
+	for _, enc := range base64Encodings {
+		data, err := enc.DecodeString(s)
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("%w: %v", ErrInvalidBase64, lastErr)
+}
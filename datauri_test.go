@@ -0,0 +1,92 @@
+package sysocr
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// base64 编码为 "+/+/+/8="，同时包含 URL 安全字母表替换的字符和填充
+var dataURITestData = []byte{0xfb, 0xff, 0xbf, 0xfb, 0xff}
+
+func TestDecodeDataURI(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		uri     string
+		want    []byte
+		wantErr error
+	}{
+		{"base64", "data:image/png;base64,+/+/+/8=", dataURITestData, nil},
+		{"uppercase scheme", "DATA:image/png;base64,+/+/+/8=", dataURITestData, nil},
+		{"parameters", "data:image/jpeg;name=a.jpg;base64,+/+/+/8=", dataURITestData, nil},
+		{"percent-encoded base64", "data:image/png;base64,%2B%2F%2B%2F%2B%2F8%3D", dataURITestData, nil},
+		{"url-safe", "data:image/png;base64,-_-_-_8=", dataURITestData, nil},
+		{"unpadded", "data:image/png;base64,+/+/+/8", dataURITestData, nil},
+		{"url-safe unpadded", "data:image/png;base64,-_-_-_8", dataURITestData, nil},
+		{"percent-encoded", "data:image/png,%FB%FF%BF%FB%FF", dataURITestData, nil},
+		{"missing comma", "data:image/png;base64", nil, ErrInvalidDataURI},
+		{"not a data URI", "https://example.com/a.png", nil, ErrInvalidDataURI},
+		{"bad media type", "data:image/;base64,+/+/+/8=", nil, ErrInvalidDataURI},
+		{"bad escape", "data:image/png;base64,%zz", nil, ErrInvalidDataURI},
+		{"empty payload", "data:image/png,", nil, ErrInvalidDataURI},
+		{"not base64", "data:image/png;base64,not*base64", nil, ErrInvalidBase64},
+		{"empty base64", "data:image/png;base64,", nil, ErrInvalidBase64},
+		// 按 RFC 2397，省略媒体类型时为 text/plain
+		{"default media type", "data:,hello", nil, ErrUnsupportedMediaType},
+		{"default media type base64", "data:;base64,aGVsbG8=", nil, ErrUnsupportedMediaType},
+		{"text", "data:text/plain;base64,aGVsbG8=", nil, ErrUnsupportedMediaType},
+		{"svg", "data:image/svg+xml,<svg/>", nil, ErrUnsupportedMediaType},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeDataURI(tt.uri)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Fatalf("data = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeBase64(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		s       string
+		want    []byte
+		wantErr error
+	}{
+		{"standard", "+/+/+/8=", dataURITestData, nil},
+		{"unpadded", "+/+/+/8", dataURITestData, nil},
+		{"url-safe", "-_-_-_8=", dataURITestData, nil},
+		{"url-safe unpadded", "-_-_-_8", dataURITestData, nil},
+		// 按列换行的输出
+		{"whitespace", " +/+/\r\n+/8=\t\n", dataURITestData, nil},
+		{"data URI", "data:image/png;base64,+/+/+/8=", dataURITestData, nil},
+		{"data URI media type", "data:text/html;base64,+/+/+/8=", nil, ErrUnsupportedMediaType},
+		{"invalid", "not*base64", nil, ErrInvalidBase64},
+		{"mixed alphabets", "+_+/+/8=", nil, ErrInvalidBase64},
+		{"empty", " \n", nil, ErrInvalidBase64},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeBase64(tt.s)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Fatalf("data = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveInputDataURI(t *testing.T) {
+	// Input.URL 中的 data URI 在本地解码，不发起请求
+	data, err := resolveInput(t.Context(), Input{URL: "data:image/png;base64,+/+/+/8="}, FetchOptions{}, 0)
+	if err != nil || !bytes.Equal(data, dataURITestData) {
+		t.Fatalf("resolveInput = %x, %v", data, err)
+	}
+	if _, err := resolveInput(t.Context(), Input{URL: "data:text/plain,x"}, FetchOptions{}, 0); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Fatalf("err = %v, want ErrUnsupportedMediaType", err)
+	}
+}
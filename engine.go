@@ -0,0 +1,141 @@
+package sysocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrEngineNotFound 表示指定名称的引擎未注册。
+var ErrEngineNotFound = errors.New("sysocr: engine not found")
+
+// Engine 是 OCR 后端的抽象，macOS Vision 与 Windows.Media.Ocr 都以 Engine 的形式注册。
+//
+// 实现只需填充 Result.Blocks（以及可选的 Result.TextAngle），Result.Text 由 Recognize 统一拼接，
+// 未填充的 TextBlock.Quad 由 BoundingBox 补齐。
+// 不提供置信度的实现应将 Capabilities.Confidence 设为 false，此时未设置的 Confidence 视为 UnknownConfidence。
+// 实现必须可以被多个 goroutine 并发调用，并在 ctx 取消后尽快返回。
+type Engine interface {
+	// Name 返回引擎的注册名称，如 "vision"、"winrt"。
+	Name() string
+	// Capabilities 描述引擎支持的功能。
+	Capabilities() Capabilities
+	// Recognize 对图片字节数据进行识别。
+	Recognize(ctx context.Context, data []byte, opts EngineOptions) (*Result, error)
+}
+
+// EngineOptions 是传递给 Engine 的识别参数。
+type EngineOptions struct {
+	Languages     []string // 语言提示，可能为空
+	MaxCandidates int      // 每行最多返回的候选结果数量，0 表示不需要
+}
+
+// Capabilities 描述引擎支持的功能。
+type Capabilities struct {
+	LanguageHints     bool // 是否使用 EngineOptions.Languages
+	Words             bool // 是否提供 TextBlock.Words
+	Confidence        bool // 是否提供 TextBlock.Confidence
+	Candidates        bool // 是否提供 TextBlock.Candidates
+	MaxImageDimension int  // 支持的最大边长（像素），0 表示不限制
+
+	// Formats 是引擎能够解码的图片格式，为空表示 DetectImage 支持的所有格式。
+	// 其他格式的输入在调用引擎之前返回 ErrUnsupportedFormat。
+	Formats []ImageFormat
+	// RawInput 为 true 时，DetectImage 无法识别的数据也原样传给引擎，
+	// 此时 Result.ImageWidth、ImageHeight 由引擎填充，否则为 0。
+	RawInput bool
+}
+
+var (
+	enginesMu     sync.RWMutex
+	engines       = make(map[string]Engine)
+	defaultEngine string
+)
+
+// Register 注册一个引擎，第一个注册的引擎成为默认引擎。
+// 如果 e 为 nil、名称为空或名称已被注册则 panic。
+func Register(e Engine) {
+	if e == nil {
+		panic("sysocr: Register engine is nil")
+	}
+	name := e.Name()
+	if name == "" {
+		panic("sysocr: Register engine with empty name")
+	}
+
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	if _, dup := engines[name]; dup {
+		panic("sysocr: Register called twice for engine " + name)
+	}
+	engines[name] = e
+	if defaultEngine == "" {
+		defaultEngine = name
+	}
+}
+
+// Unregister 移除指定名称的引擎，主要用于测试。
+// 如果被移除的是默认引擎，默认引擎将被清空。
+func Unregister(name string) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	delete(engines, name)
+	if defaultEngine == name {
+		defaultEngine = ""
+	}
+}
+
+// SetDefault 将已注册的引擎设置为默认引擎。
+func SetDefault(name string) error {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	if _, ok := engines[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrEngineNotFound, name)
+	}
+	defaultEngine = name
+	return nil
+}
+
+// Lookup 按名称查找已注册的引擎。
+func Lookup(name string) (Engine, bool) {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	e, ok := engines[name]
+	return e, ok
+}
+
+// DefaultEngine 返回当前默认引擎，没有可用引擎时返回 nil。
+func DefaultEngine() Engine {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	return engines[defaultEngine]
+}
+
+// Engines 返回所有已注册引擎的名称（按字母排序）。
+func Engines() []string {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectEngine 返回 name 对应的引擎，name 为空时返回默认引擎。
+func selectEngine(name string) (Engine, error) {
+	if name == "" {
+		if e := DefaultEngine(); e != nil {
+			return e, nil
+		}
+		return nil, ErrUnsupportedPlatform
+	}
+	e, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrEngineNotFound, name)
+	}
+	return e, nil
+}
@@ -0,0 +1,261 @@
+package sysocr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// testPNG 返回一张 w×h 的空白 PNG 图片。
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// registerTestEngine 注册 e 并在测试结束时移除它、恢复原来的默认引擎。
+func registerTestEngine(t *testing.T, e Engine) {
+	t.Helper()
+	var prev string
+	if d := DefaultEngine(); d != nil {
+		prev = d.Name()
+	}
+	Register(e)
+	t.Cleanup(func() {
+		Unregister(e.Name())
+		if prev != "" {
+			SetDefault(prev)
+		}
+	})
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	registerTestEngine(t, &FakeEngine{EngineName: "test-dup"})
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Register with a duplicate name did not panic")
+		}
+	}()
+	Register(&FakeEngine{EngineName: "test-dup"})
+}
+
+func TestRegisterInvalidPanics(t *testing.T) {
+	for name, e := range map[string]Engine{
+		"nil":        nil,
+		"empty name": emptyNameEngine{&FakeEngine{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("Register did not panic")
+				}
+			}()
+			Register(e)
+		})
+	}
+}
+
+type emptyNameEngine struct{ *FakeEngine }
+
+func (emptyNameEngine) Name() string { return "" }
+
+func TestUnregisterDefault(t *testing.T) {
+	registerTestEngine(t, &FakeEngine{EngineName: "test-default"})
+	if err := SetDefault("test-default"); err != nil {
+		t.Fatal(err)
+	}
+	Unregister("test-default")
+	if e := DefaultEngine(); e != nil {
+		t.Fatalf("DefaultEngine() = %q after unregistering the default", e.Name())
+	}
+	if _, err := Recognize(Options{Input: Input{Data: testPNG(t, 4, 4)}}); !errors.Is(err, ErrUnsupportedPlatform) {
+		t.Fatalf("Recognize without a default engine: err = %v, want ErrUnsupportedPlatform", err)
+	}
+	if _, ok := Lookup("test-default"); ok {
+		t.Fatal("Lookup found an unregistered engine")
+	}
+}
+
+func TestSetDefaultUnknown(t *testing.T) {
+	before := DefaultEngine()
+	if err := SetDefault("test-no-such-engine"); !errors.Is(err, ErrEngineNotFound) {
+		t.Fatalf("SetDefault(unknown) = %v, want ErrEngineNotFound", err)
+	}
+	if DefaultEngine() != before {
+		t.Fatal("SetDefault(unknown) changed the default engine")
+	}
+}
+
+func TestRecognizeUnknownEngine(t *testing.T) {
+	_, err := Recognize(Options{Engine: "test-no-such-engine", Input: Input{Data: testPNG(t, 4, 4)}})
+	if !errors.Is(err, ErrEngineNotFound) {
+		t.Fatalf("err = %v, want ErrEngineNotFound", err)
+	}
+}
+
+func TestEngines(t *testing.T) {
+	registerTestEngine(t, &FakeEngine{EngineName: "test-b"})
+	registerTestEngine(t, &FakeEngine{EngineName: "test-a"})
+	var got []string
+	for _, name := range Engines() {
+		if name == "test-a" || name == "test-b" {
+			got = append(got, name)
+		}
+	}
+	if len(got) != 2 || got[0] != "test-a" || got[1] != "test-b" {
+		t.Fatalf("Engines() = %v, want test-a before test-b", got)
+	}
+}
+
+func TestFakeEngineRecognize(t *testing.T) {
+	blocks := []TextBlock{
+		{Text: "hello", BoundingBox: BoundingBox{0.1, 0.1, 0.3, 0.1}, Confidence: 0.9},
+		{Text: "world", BoundingBox: BoundingBox{0.1, 0.3, 0.3, 0.1}, Confidence: 0.8},
+	}
+	fake := &FakeEngine{EngineName: "test-fake", Result: &Result{Blocks: blocks, TextAngle: 2.5}}
+	registerTestEngine(t, fake)
+
+	result, err := Recognize(Options{Engine: "test-fake", Input: Input{Data: testPNG(t, 200, 100)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Text != "hello\nworld" {
+		t.Errorf("Text = %q", result.Text)
+	}
+	if result.TextAngle != 2.5 {
+		t.Errorf("TextAngle = %v, want the engine's 2.5", result.TextAngle)
+	}
+	if result.ImageWidth != 200 || result.ImageHeight != 100 {
+		t.Errorf("image size = %dx%d, want 200x100", result.ImageWidth, result.ImageHeight)
+	}
+	if result.Blocks[0].Quad != blocks[0].BoundingBox.Quad() {
+		t.Errorf("Quad = %v, want filled from BoundingBox", result.Blocks[0].Quad)
+	}
+	if result.Page == nil || len(result.Page.Lines()) != 2 {
+		t.Errorf("Page = %+v, want 2 lines", result.Page)
+	}
+
+	// 返回的结果是副本，修改它不影响 FakeEngine.Result
+	result.Blocks[0].Text = "changed"
+	if fake.Result.Blocks[0].Text != "hello" {
+		t.Error("modifying the result changed FakeEngine.Result")
+	}
+}
+
+func TestFakeEngineCopiesResult(t *testing.T) {
+	want := &Result{
+		Blocks:      []TextBlock{{Text: "a"}},
+		TextAngle:   -3,
+		ImageWidth:  10,
+		ImageHeight: 20,
+		Orientation: OrientationRotate90,
+	}
+	got, err := (&FakeEngine{Result: want}).Recognize(context.Background(), nil, EngineOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == want {
+		t.Fatal("Recognize returned FakeEngine.Result itself")
+	}
+	if got.TextAngle != want.TextAngle || got.ImageWidth != want.ImageWidth ||
+		got.ImageHeight != want.ImageHeight || got.Orientation != want.Orientation || len(got.Blocks) != 1 {
+		t.Fatalf("Recognize = %+v, want a copy of %+v", got, want)
+	}
+}
+
+func TestFakeEngineRecognizeTwice(t *testing.T) {
+	// 没有置信度的预设结果：Recognize 会把单词的置信度改为 UnknownConfidence，不能影响预设结果
+	fake := &FakeEngine{
+		EngineName: "test-twice",
+		Result: &Result{Blocks: []TextBlock{{
+			Text:        "hi",
+			BoundingBox: BoundingBox{0.1, 0.1, 0.2, 0.1},
+			Words:       []Word{{Text: "hi", BoundingBox: BoundingBox{0.1, 0.1, 0.2, 0.1}}},
+			Candidates:  []Candidate{{Text: "hi"}, {Text: "hl"}},
+		}}},
+	}
+	want := fake.Result.Blocks[0]
+	want.Words = slices.Clone(want.Words)
+	want.Candidates = slices.Clone(want.Candidates)
+	registerTestEngine(t, fake)
+
+	for i := range 2 {
+		result, err := Recognize(Options{
+			Engine:        "test-twice",
+			Input:         Input{Data: []byte("placeholder")},
+			MinConfidence: 0.5,
+			MaxCandidates: 2,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Blocks) != 1 || result.Blocks[0].Words[0].Confidence != UnknownConfidence {
+			t.Fatalf("call %d: blocks = %+v", i+1, result.Blocks)
+		}
+		got := fake.Result.Blocks[0]
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("call %d changed FakeEngine.Result: %+v, want %+v", i+1, got, want)
+		}
+		if fake.Capabilities().Confidence {
+			t.Fatalf("call %d: Capabilities().Confidence = true", i+1)
+		}
+	}
+}
+
+func TestFakeEngineErr(t *testing.T) {
+	errBoom := errors.New("boom")
+	registerTestEngine(t, &FakeEngine{EngineName: "test-err", Err: errBoom})
+	if _, err := Recognize(Options{Engine: "test-err", Input: Input{Data: testPNG(t, 4, 4)}}); !errors.Is(err, errBoom) {
+		t.Fatalf("err = %v, want %v", err, errBoom)
+	}
+}
+
+func TestFakeEngineFunc(t *testing.T) {
+	var gotOpts EngineOptions
+	registerTestEngine(t, &FakeEngine{
+		EngineName: "test-func",
+		Func: func(ctx context.Context, data []byte, opts EngineOptions) (*Result, error) {
+			gotOpts = opts
+			return &Result{}, nil
+		},
+	})
+	_, err := Recognize(Options{
+		Engine:        "test-func",
+		Input:         Input{Data: testPNG(t, 4, 4)},
+		Languages:     []string{"en"},
+		MaxCandidates: 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotOpts.Languages) != 1 || gotOpts.Languages[0] != "en" || gotOpts.MaxCandidates != 3 {
+		t.Fatalf("engine options = %+v", gotOpts)
+	}
+}
+
+func TestRecognizeCanceled(t *testing.T) {
+	registerTestEngine(t, &FakeEngine{EngineName: "test-cancel"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := RecognizeContext(ctx, Options{Engine: "test-cancel", Input: Input{Data: testPNG(t, 4, 4)}})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+func near(a, b float64) bool {
+	d := a - b
+	return d < 1e-9 && d > -1e-9
+}
+
+func boxNear(a, b BoundingBox) bool {
+	return near(a.X, b.X) && near(a.Y, b.Y) && near(a.Width, b.Width) && near(a.Height, b.Height)
+}
@@ -0,0 +1,44 @@
+package sysocr
+
+import (
+	"errors"
+	"math"
+	"strconv"
+)
+
+// ErrUnknownImageSize 表示导出像素坐标时既没有传入图片尺寸，Result 中也没有记录。
+var ErrUnknownImageSize = errors.New("sysocr: image size is required for pixel coordinates")
+
+// outputSize 返回导出时使用的图片尺寸，宽高均为 0 时使用 Result 中记录的尺寸。
+func (r *Result) outputSize(imageWidth, imageHeight int) (int, int, error) {
+	if imageWidth == 0 && imageHeight == 0 {
+		imageWidth, imageHeight = r.ImageWidth, r.ImageHeight
+	}
+	if imageWidth <= 0 || imageHeight <= 0 {
+		return 0, 0, ErrUnknownImageSize
+	}
+	return imageWidth, imageHeight, nil
+}
+
+// layout 返回用于导出的版面结构，Result.Page 为空时由 Blocks 分析得到。
+func (r *Result) layout(imageWidth, imageHeight int) *Page {
+	if r.Page != nil {
+		return r.Page
+	}
+	return AnalyzeLayout(r.Blocks, imageWidth, imageHeight)
+}
+
+// normalizeBox 将像素（或其他单位）坐标按页面尺寸归一化。
+func normalizeBox(x, y, width, height, pageWidth, pageHeight float64) BoundingBox {
+	return BoundingBox{
+		X:      x / pageWidth,
+		Y:      y / pageHeight,
+		Width:  width / pageWidth,
+		Height: height / pageHeight,
+	}
+}
+
+// formatConfidence 将 0-1 的置信度格式化为最多 4 位小数。
+func formatConfidence(c float64) string {
+	return strconv.FormatFloat(math.Round(c*1e4)/1e4, 'f', -1, 64)
+}
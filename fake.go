@@ -0,0 +1,78 @@
+package sysocr
+
+import "context"
+
+// FakeEngine 是一个不调用任何系统接口的引擎，用于在任意平台上对调用方进行单元测试。
+// 它声明了 Capabilities.RawInput，因此输入可以是任意占位数据，不必是真实的图片。
+//
+//	sysocr.Register(&sysocr.FakeEngine{Result: &sysocr.Result{Blocks: blocks}})
+//	result, err := sysocr.Recognize(sysocr.Options{Engine: "fake", Input: input})
+type FakeEngine struct {
+	EngineName string  // 注册名称，默认为 "fake"
+	Result     *Result // 每次识别返回的结果（返回副本）
+	Err        error   // 非 nil 时 Recognize 直接返回该错误
+
+	// Func 不为 nil 时替代 Result/Err，可用于检查传入的数据和参数。
+	Func func(ctx context.Context, data []byte, opts EngineOptions) (*Result, error)
+}
+
+// Name 实现 Engine 接口。
+func (f *FakeEngine) Name() string {
+	if f.EngineName == "" {
+		return "fake"
+	}
+	return f.EngineName
+}
+
+// Capabilities 实现 Engine 接口。
+// 只有设置了 Func 或 Result 中有非零的置信度时才声明提供置信度，
+// 因此未设置 Confidence 的预设结果不会被 Options.MinConfidence 过滤。
+func (f *FakeEngine) Capabilities() Capabilities {
+	return Capabilities{LanguageHints: true, Words: true, Confidence: f.hasConfidence(), Candidates: true, RawInput: true}
+}
+
+// hasConfidence 报告预设结果是否包含置信度。
+func (f *FakeEngine) hasConfidence() bool {
+	if f.Func != nil {
+		return true
+	}
+	if f.Result == nil {
+		return false
+	}
+	for _, b := range f.Result.Blocks {
+		if b.Confidence != 0 {
+			return true
+		}
+		for _, w := range b.Words {
+			if w.Confidence != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Recognize 实现 Engine 接口。
+func (f *FakeEngine) Recognize(ctx context.Context, data []byte, opts EngineOptions) (*Result, error) {
+	if f.Func != nil {
+		return f.Func(ctx, data, opts)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	result := &Result{}
+	if f.Result != nil {
+		*result = *f.Result
+		// 单词和候选结果也要复制，Recognize 会修改其中的置信度
+		result.Blocks = append([]TextBlock(nil), f.Result.Blocks...)
+		for i := range result.Blocks {
+			b := &result.Blocks[i]
+			b.Words = append([]Word(nil), b.Words...)
+			b.Candidates = append([]Candidate(nil), b.Candidates...)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,149 @@
+package sysocr
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+// Empty 报告边界框的面积是否为 0。
+func (b BoundingBox) Empty() bool {
+	return b.Width <= 0 || b.Height <= 0
+}
+
+// Area 返回边界框的归一化面积，空边界框为 0。
+func (b BoundingBox) Area() float64 {
+	if b.Empty() {
+		return 0
+	}
+	return b.Width * b.Height
+}
+
+// Pixels 将归一化的边界框转换为宽 width、高 height 的图片中的像素矩形。
+// 边缘向外取整以完整覆盖文本，结果裁剪到图片范围内。
+func (b BoundingBox) Pixels(width, height int) image.Rectangle {
+	// 容忍浮点误差，避免 0.15*600 = 90.00000000000001 被取整为 91
+	const eps = 1e-6
+	r := image.Rect(
+		int(math.Floor(b.X*float64(width)+eps)),
+		int(math.Floor(b.Y*float64(height)+eps)),
+		int(math.Ceil((b.X+b.Width)*float64(width)-eps)),
+		int(math.Ceil((b.Y+b.Height)*float64(height)-eps)),
+	)
+	return r.Intersect(image.Rect(0, 0, width, height))
+}
+
+// pixelRect 是换算为像素比例的矩形，用于版面分析、阅读顺序和表格提取中的距离比较。
+type pixelRect struct {
+	x0, y0, x1, y1 float64
+}
+
+func (r pixelRect) height() float64  { return r.y1 - r.y0 }
+func (r pixelRect) centerY() float64 { return (r.y0 + r.y1) / 2 }
+
+// pixelScale 返回将归一化坐标还原为像素比例的系数，图片尺寸未知时按 1:1 处理。
+func pixelScale(imageWidth, imageHeight int) (sx, sy float64) {
+	if imageWidth <= 0 || imageHeight <= 0 {
+		return 1, 1
+	}
+	return float64(imageWidth), float64(imageHeight)
+}
+
+// pixelRect 将边界框按 sx、sy 缩放，宽高为负的边界框（如来自外部文件）按其两个角点取正。
+func (b BoundingBox) pixelRect(sx, sy float64) pixelRect {
+	x0, x1 := b.X*sx, (b.X+b.Width)*sx
+	y0, y1 := b.Y*sy, (b.Y+b.Height)*sy
+	return pixelRect{x0: min(x0, x1), y0: min(y0, y1), x1: max(x0, x1), y1: max(y0, y1)}
+}
+
+// Union 返回同时包含 b 和 o 的最小边界框。空边界框不参与计算。
+func (b BoundingBox) Union(o BoundingBox) BoundingBox {
+	if b.Empty() {
+		return o
+	}
+	if o.Empty() {
+		return b
+	}
+	minX, minY := min(b.X, o.X), min(b.Y, o.Y)
+	maxX, maxY := max(b.X+b.Width, o.X+o.Width), max(b.Y+b.Height, o.Y+o.Height)
+	return BoundingBox{X: minX, Y: minY, Width: maxX - minX, Height: maxY - minY}
+}
+
+// Intersect 返回 b 和 o 的交集，不相交时返回零值。
+func (b BoundingBox) Intersect(o BoundingBox) BoundingBox {
+	minX, minY := max(b.X, o.X), max(b.Y, o.Y)
+	maxX, maxY := min(b.X+b.Width, o.X+o.Width), min(b.Y+b.Height, o.Y+o.Height)
+	if maxX <= minX || maxY <= minY {
+		return BoundingBox{}
+	}
+	return BoundingBox{X: minX, Y: minY, Width: maxX - minX, Height: maxY - minY}
+}
+
+// IoU 返回 b 和 o 的交并比（0-1），常用于判断两个检测结果是否指向同一处文本。
+func (b BoundingBox) IoU(o BoundingBox) float64 {
+	inter := b.Intersect(o).Area()
+	if inter == 0 {
+		return 0
+	}
+	return inter / (b.Area() + o.Area() - inter)
+}
+
+// Contains 报告点 p 是否位于边界框内（含左上边缘，不含右下边缘）。
+func (b BoundingBox) Contains(p Point) bool {
+	return p.X >= b.X && p.X < b.X+b.Width && p.Y >= b.Y && p.Y < b.Y+b.Height
+}
+
+// Quad 返回与边界框相同的轴对齐四边形。
+func (b BoundingBox) Quad() Quad {
+	return Quad{
+		{b.X, b.Y},
+		{b.X + b.Width, b.Y},
+		{b.X + b.Width, b.Y + b.Height},
+		{b.X, b.Y + b.Height},
+	}
+}
+
+// IsZero 报告四边形是否为零值（引擎未提供）。
+func (q Quad) IsZero() bool {
+	return q == Quad{}
+}
+
+// Bounds 返回包含四边形的最小轴对齐边界框。
+func (q Quad) Bounds() BoundingBox {
+	minX, minY := q[0].X, q[0].Y
+	maxX, maxY := minX, minY
+	for _, p := range q[1:] {
+		minX, maxX = min(minX, p.X), max(maxX, p.X)
+		minY, maxY = min(minY, p.Y), max(maxY, p.Y)
+	}
+	return BoundingBox{X: minX, Y: minY, Width: maxX - minX, Height: maxY - minY}
+}
+
+// Angle 返回四边形上边相对水平方向的顺时针角度（度）。
+// 坐标是归一化的，因此需要图片的像素宽高来还原真实角度。
+func (q Quad) Angle(imageWidth, imageHeight int) float64 {
+	dx := (q[1].X - q[0].X) * float64(imageWidth)
+	dy := (q[1].Y - q[0].Y) * float64(imageHeight)
+	if dx == 0 && dy == 0 {
+		return 0
+	}
+	// 图片坐标系 y 轴向下，atan2 的正方向即顺时针
+	return math.Atan2(dy, dx) * 180 / math.Pi
+}
+
+// estimateTextAngle 取各行四边形角度的中位数作为页面的文本倾斜角度。
+func estimateTextAngle(blocks []TextBlock, imageWidth, imageHeight int) float64 {
+	if len(blocks) == 0 || imageWidth <= 0 || imageHeight <= 0 {
+		return 0
+	}
+	angles := make([]float64, 0, len(blocks))
+	for _, b := range blocks {
+		angles = append(angles, b.Quad.Angle(imageWidth, imageHeight))
+	}
+	sort.Float64s(angles)
+	mid := len(angles) / 2
+	if len(angles)%2 == 0 {
+		return (angles[mid-1] + angles[mid]) / 2
+	}
+	return angles[mid]
+}
@@ -0,0 +1,192 @@
+package sysocr
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestBoundingBoxPixels(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		box  BoundingBox
+		w, h int
+		want image.Rectangle
+	}{
+		{"exact", BoundingBox{0.25, 0.5, 0.5, 0.25}, 100, 40, image.Rect(25, 20, 75, 30)},
+		// 0.15*600 = 90.00000000000001，0.1+0.2 = 0.30000000000000004，浮点误差不会多取一个像素
+		{"float error left", BoundingBox{0.15, 0, 0.1, 1}, 600, 10, image.Rect(90, 0, 150, 10)},
+		{"float error right", BoundingBox{0.1, 0, 0.2, 1}, 100, 10, image.Rect(10, 0, 30, 10)},
+		// 边缘向外取整
+		{"outward", BoundingBox{0.101, 0.101, 0.1, 0.1}, 100, 100, image.Rect(10, 10, 21, 21)},
+		{"clipped", BoundingBox{-0.05, 0.9, 0.2, 0.2}, 100, 100, image.Rect(0, 90, 15, 100)},
+		{"outside", BoundingBox{1.5, 0, 0.2, 0.2}, 100, 100, image.Rectangle{}},
+		{"empty", BoundingBox{}, 100, 100, image.Rectangle{}},
+	} {
+		if got := tt.box.Pixels(tt.w, tt.h); got != tt.want {
+			t.Errorf("%s: Pixels(%d, %d) = %v, want %v", tt.name, tt.w, tt.h, got, tt.want)
+		}
+	}
+}
+
+func TestBoundingBoxUnionIntersect(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		a, b         BoundingBox
+		union, inter BoundingBox
+	}{
+		{
+			name:  "overlap",
+			a:     BoundingBox{0.1, 0.1, 0.4, 0.4},
+			b:     BoundingBox{0.3, 0.2, 0.4, 0.5},
+			union: BoundingBox{0.1, 0.1, 0.6, 0.6},
+			inter: BoundingBox{0.3, 0.2, 0.2, 0.3},
+		},
+		{
+			name:  "contained",
+			a:     BoundingBox{0, 0, 1, 1},
+			b:     BoundingBox{0.2, 0.3, 0.1, 0.1},
+			union: BoundingBox{0, 0, 1, 1},
+			inter: BoundingBox{0.2, 0.3, 0.1, 0.1},
+		},
+		{
+			name:  "disjoint",
+			a:     BoundingBox{0, 0, 0.2, 0.2},
+			b:     BoundingBox{0.5, 0.5, 0.2, 0.2},
+			union: BoundingBox{0, 0, 0.7, 0.7},
+		},
+		{
+			// 只有边相接时交集为空
+			name:  "touching",
+			a:     BoundingBox{0, 0, 0.5, 0.5},
+			b:     BoundingBox{0.5, 0, 0.5, 0.5},
+			union: BoundingBox{0, 0, 1, 0.5},
+		},
+		{
+			// 空边界框不参与并集
+			name:  "empty",
+			a:     BoundingBox{},
+			b:     BoundingBox{0.5, 0.5, 0.2, 0.2},
+			union: BoundingBox{0.5, 0.5, 0.2, 0.2},
+		},
+	} {
+		for _, pair := range [][2]BoundingBox{{tt.a, tt.b}, {tt.b, tt.a}} {
+			if got := pair[0].Union(pair[1]); !boxNear(got, tt.union) {
+				t.Errorf("%s: %+v.Union(%+v) = %+v, want %+v", tt.name, pair[0], pair[1], got, tt.union)
+			}
+			if got := pair[0].Intersect(pair[1]); !boxNear(got, tt.inter) {
+				t.Errorf("%s: %+v.Intersect(%+v) = %+v, want %+v", tt.name, pair[0], pair[1], got, tt.inter)
+			}
+		}
+	}
+}
+
+func TestBoundingBoxIoU(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		a, b BoundingBox
+		want float64
+	}{
+		{"same", BoundingBox{0.1, 0.1, 0.2, 0.2}, BoundingBox{0.1, 0.1, 0.2, 0.2}, 1},
+		// 交集 0.1×0.2，并集 0.04+0.04-0.02
+		{"half", BoundingBox{0, 0, 0.2, 0.2}, BoundingBox{0.1, 0, 0.2, 0.2}, 1.0 / 3},
+		{"contained", BoundingBox{0, 0, 0.4, 0.4}, BoundingBox{0, 0, 0.2, 0.2}, 0.25},
+		{"disjoint", BoundingBox{0, 0, 0.2, 0.2}, BoundingBox{0.5, 0.5, 0.2, 0.2}, 0},
+		{"empty", BoundingBox{}, BoundingBox{}, 0},
+	} {
+		if got := tt.a.IoU(tt.b); !near(got, tt.want) {
+			t.Errorf("%s: IoU = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := tt.b.IoU(tt.a); !near(got, tt.want) {
+			t.Errorf("%s: reversed IoU = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBoundingBoxContains(t *testing.T) {
+	b := BoundingBox{0.25, 0.5, 0.5, 0.25}
+	for _, tt := range []struct {
+		p    Point
+		want bool
+	}{
+		{Point{0.5, 0.625}, true},
+		{Point{0.25, 0.5}, true},    // 左上角包含在内
+		{Point{0.75, 0.625}, false}, // 右边缘不包含
+		{Point{0.5, 0.75}, false},   // 下边缘不包含
+		{Point{0.1, 0.625}, false},
+		{Point{0.5, 0.3}, false},
+	} {
+		if got := b.Contains(tt.p); got != tt.want {
+			t.Errorf("Contains(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if (BoundingBox{0.5, 0.5, 0, 0}).Contains(Point{0.5, 0.5}) {
+		t.Error("empty box contains its origin")
+	}
+}
+
+func TestQuadAngle(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		q    Quad
+		w, h int
+		want float64
+	}{
+		{"horizontal", BoundingBox{0.1, 0.1, 0.5, 0.2}.Quad(), 100, 100, 0},
+		// 归一化坐标中的 (0.5, 0.25) 在 100×200 的图片中是 (50, 50)
+		{"clockwise", Quad{{0, 0}, {0.5, 0.25}}, 100, 200, 45},
+		{"counterclockwise", Quad{{0, 0.5}, {0.5, 0.25}}, 100, 200, -45},
+		{"aspect", Quad{{0, 0}, {0.5, 0.5}}, 200, 100, math.Atan(0.5) * 180 / math.Pi},
+		{"vertical", Quad{{0.5, 0}, {0.5, 0.5}}, 100, 100, 90},
+		{"zero", Quad{}, 100, 100, 0},
+	} {
+		if got := tt.q.Angle(tt.w, tt.h); !near(got, tt.want) {
+			t.Errorf("%s: Angle = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateTextAngle(t *testing.T) {
+	// 上边在 100×100 的图片中向右下倾斜 deg 度
+	skewed := func(deg float64) TextBlock {
+		dy := 0.5 * math.Tan(deg*math.Pi/180)
+		return TextBlock{Quad: Quad{{0.1, 0.1}, {0.6, 0.1 + dy}, {0.6, 0.2 + dy}, {0.1, 0.2}}}
+	}
+	for _, tt := range []struct {
+		name   string
+		angles []float64
+		want   float64
+	}{
+		{"odd", []float64{3, -1, 2}, 2},
+		{"even", []float64{1, 4, 2, 3}, 2.5},
+		// 个别离群的行不影响结果
+		{"outlier", []float64{2, 2, 45}, 2},
+		{"empty", nil, 0},
+	} {
+		var blocks []TextBlock
+		for _, a := range tt.angles {
+			blocks = append(blocks, skewed(a))
+		}
+		if got := estimateTextAngle(blocks, 100, 100); !near(got, tt.want) {
+			t.Errorf("%s: estimateTextAngle = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if got := estimateTextAngle([]TextBlock{skewed(5)}, 0, 0); got != 0 {
+		t.Errorf("unknown size: estimateTextAngle = %v, want 0", got)
+	}
+}
+
+func TestPixelRect(t *testing.T) {
+	sx, sy := pixelScale(200, 100)
+	if got, want := (BoundingBox{0.1, 0.2, 0.5, 0.3}).pixelRect(sx, sy), (pixelRect{20, 20, 120, 50}); got != want {
+		t.Errorf("pixelRect = %+v, want %+v", got, want)
+	}
+	// 宽高为负时取两个角点
+	if got, want := (BoundingBox{0.625, 0.5, -0.5, -0.25}).pixelRect(sx, sy), (pixelRect{25, 25, 125, 50}); got != want {
+		t.Errorf("negative size: pixelRect = %+v, want %+v", got, want)
+	}
+	// 尺寸未知时保持归一化坐标
+	if sx, sy := pixelScale(0, 100); sx != 1 || sy != 1 {
+		t.Errorf("pixelScale(0, 100) = %v, %v, want 1, 1", sx, sy)
+	}
+}
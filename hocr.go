@@ -0,0 +1,80 @@
+package sysocr
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+)
+
+// hocrHeader 是 hOCR 文档的头部，ocr-capabilities 列出输出中使用的元素。
+const hocrHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">
+<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="en" lang="en">
+ <head>
+  <title></title>
+  <meta http-equiv="Content-Type" content="text/html;charset=utf-8"/>
+  <meta name="ocr-system" content="sysocr"/>
+  <meta name="ocr-capabilities" content="ocr_page ocr_carea ocr_par ocr_line ocrx_word"/>
+ </head>
+ <body>
+`
+
+// WriteHOCR 以 hOCR 格式写出识别结果：ocr_page → ocr_carea → ocr_par → ocr_line → ocrx_word，
+// 层级来自 Result.Page（为空时由 Blocks 分析得到）。
+//
+// bbox 以像素为单位，imageWidth、imageHeight 为 0 时使用 Result.ImageWidth、Result.ImageHeight。
+// 置信度已知时以 x_wconf（0-100）写入；引擎不提供单词时用 SplitWords 近似。
+func (r *Result) WriteHOCR(w io.Writer, imageWidth, imageHeight int) error {
+	imageWidth, imageHeight, err := r.outputSize(imageWidth, imageHeight)
+	if err != nil {
+		return err
+	}
+	page := r.layout(imageWidth, imageHeight)
+
+	bw := bufio.NewWriter(w)
+	bbox := func(b BoundingBox) string {
+		p := b.Pixels(imageWidth, imageHeight)
+		return fmt.Sprintf("bbox %d %d %d %d", p.Min.X, p.Min.Y, p.Max.X, p.Max.Y)
+	}
+
+	bw.WriteString(hocrHeader)
+	fmt.Fprintf(bw, "  <div class=\"ocr_page\" id=\"page_1\" title=\"image &quot;&quot;; bbox 0 0 %d %d; ppageno 0\">\n",
+		imageWidth, imageHeight)
+	var areaID, parID, lineID, wordID int
+	for _, region := range page.Regions {
+		areaID++
+		fmt.Fprintf(bw, "   <div class=\"ocr_carea\" id=\"block_1_%d\" title=\"%s\">\n", areaID, bbox(region.BoundingBox))
+		for _, para := range region.Paragraphs {
+			parID++
+			fmt.Fprintf(bw, "    <p class=\"ocr_par\" id=\"par_1_%d\" title=\"%s\">\n", parID, bbox(para.BoundingBox))
+			for _, line := range para.Lines {
+				lineID++
+				fmt.Fprintf(bw, "     <span class=\"ocr_line\" id=\"line_1_%d\" title=\"%s\">", lineID, bbox(line.BoundingBox))
+				for i, word := range SplitWords(line) {
+					wordID++
+					if i > 0 {
+						bw.WriteByte(' ')
+					}
+					title := bbox(word.BoundingBox)
+					conf := word.Confidence
+					if len(line.Words) == 0 {
+						// 近似的单词沿用行的置信度
+						conf = line.Confidence
+					}
+					if conf >= 0 {
+						title += fmt.Sprintf("; x_wconf %d", int(math.Round(conf*100)))
+					}
+					fmt.Fprintf(bw, "<span class=\"ocrx_word\" id=\"word_1_%d\" title=\"%s\">%s</span>",
+						wordID, title, html.EscapeString(word.Text))
+				}
+				bw.WriteString("</span>\n")
+			}
+			bw.WriteString("    </p>\n")
+		}
+		bw.WriteString("   </div>\n")
+	}
+	bw.WriteString("  </div>\n </body>\n</html>\n")
+	return bw.Flush()
+}
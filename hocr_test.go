@@ -0,0 +1,112 @@
+package sysocr
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// checkGolden 将 got 与 testdata/name 比较，指定 -update 时改为写入该文件。
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (run go test -update to accept):\n%s", path, got)
+	}
+}
+
+func TestWriteHOCR(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		blocks []TextBlock
+	}{
+		{
+			name: "words",
+			blocks: []TextBlock{
+				{
+					Text: "Hello world", BoundingBox: BoundingBox{0.1, 0.1, 0.5, 0.1}, Confidence: 0.93,
+					Words: []Word{
+						{Text: "Hello", BoundingBox: BoundingBox{0.1, 0.1, 0.2, 0.1}, Confidence: 0.96},
+						{Text: "world", BoundingBox: BoundingBox{0.35, 0.1, 0.25, 0.1}, Confidence: 0.9},
+					},
+				},
+				{
+					Text: "again", BoundingBox: BoundingBox{0.1, 0.22, 0.2, 0.1}, Confidence: 0.8,
+					Words: []Word{{Text: "again", BoundingBox: BoundingBox{0.1, 0.22, 0.2, 0.1}, Confidence: 0.8}},
+				},
+			},
+		},
+		{
+			// 引擎不提供单词时用 SplitWords 近似，沿用行的置信度
+			name: "split",
+			blocks: []TextBlock{
+				{Text: "no word data", BoundingBox: BoundingBox{0.1, 0.1, 0.6, 0.1}, Confidence: 0.75},
+				{Text: "中文 文本", BoundingBox: BoundingBox{0.1, 0.5, 0.45, 0.1}, Confidence: 0.5},
+			},
+		},
+		{
+			name: "unknown confidence",
+			blocks: []TextBlock{
+				{Text: "unknown line", BoundingBox: BoundingBox{0.1, 0.1, 0.6, 0.1}, Confidence: UnknownConfidence},
+				{
+					Text: "mixed words", BoundingBox: BoundingBox{0.1, 0.22, 0.6, 0.1}, Confidence: UnknownConfidence,
+					Words: []Word{
+						{Text: "mixed", BoundingBox: BoundingBox{0.1, 0.22, 0.3, 0.1}, Confidence: 0.7},
+						{Text: "words", BoundingBox: BoundingBox{0.45, 0.22, 0.25, 0.1}, Confidence: UnknownConfidence},
+					},
+				},
+			},
+		},
+		{
+			name: "escape",
+			blocks: []TextBlock{
+				{Text: `<b>&amp; "quoted" 'single'`, BoundingBox: BoundingBox{0.1, 0.1, 0.8, 0.1}, Confidence: 1},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Result{Blocks: tt.blocks, ImageWidth: 200, ImageHeight: 100}
+			var buf bytes.Buffer
+			if err := r.WriteHOCR(&buf, 0, 0); err != nil {
+				t.Fatal(err)
+			}
+			// hOCR 是 XHTML，必须是格式正确的 XML
+			dec := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+			dec.Strict = true
+			for {
+				if _, err := dec.Token(); err != nil {
+					if err != io.EOF {
+						t.Fatalf("output is not well-formed XML: %v", err)
+					}
+					break
+				}
+			}
+			checkGolden(t, "hocr_"+strings.ReplaceAll(tt.name, " ", "_")+".hocr", buf.Bytes())
+		})
+	}
+}
+
+func TestWriteHOCRUnknownSize(t *testing.T) {
+	r := &Result{Blocks: []TextBlock{{Text: "x"}}}
+	if err := r.WriteHOCR(&bytes.Buffer{}, 0, 0); !errors.Is(err, ErrUnknownImageSize) {
+		t.Fatalf("err = %v, want ErrUnknownImageSize", err)
+	}
+}
@@ -0,0 +1,393 @@
+package sysocr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	ErrEmptyImage        = errors.New("sysocr: empty image")
+	ErrUnsupportedFormat = errors.New("sysocr: unsupported image format")
+	ErrInvalidImage      = errors.New("sysocr: invalid image data")
+	ErrImageTooLarge     = errors.New("sysocr: image too large")
+)
+
+// DefaultMaxImagePixels 是 Options.MaxImagePixels 为 0 时使用的像素数上限。
+const DefaultMaxImagePixels = 100_000_000
+
+// ImageFormat 表示图片的编码格式。
+type ImageFormat string
+
+const (
+	FormatPNG  ImageFormat = "png"
+	FormatJPEG ImageFormat = "jpeg"
+	FormatGIF  ImageFormat = "gif"
+	FormatBMP  ImageFormat = "bmp"
+	FormatTIFF ImageFormat = "tiff"
+	FormatWebP ImageFormat = "webp"
+	FormatHEIF ImageFormat = "heif" // 包括 iPhone 等设备拍摄的 HEIC 照片
+)
+
+// ImageInfo 描述图片的格式和尺寸（像素）。
+type ImageInfo struct {
+	Format ImageFormat
+	Width  int
+	Height int
+}
+
+// DetectImage 根据文件头识别图片格式并读取尺寸，不解码像素数据。
+// 支持 PNG、JPEG、GIF、BMP、TIFF、WebP 和 HEIF。
+func DetectImage(data []byte) (ImageInfo, error) {
+	if len(data) == 0 {
+		return ImageInfo{}, ErrEmptyImage
+	}
+
+	var info ImageInfo
+	var err error
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		info, err = pngInfo(data)
+	case bytes.HasPrefix(data, []byte("\xff\xd8")):
+		info, err = jpegInfo(data)
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		info, err = gifInfo(data)
+	case bytes.HasPrefix(data, []byte("BM")):
+		info, err = bmpInfo(data)
+	case bytes.HasPrefix(data, []byte("II*\x00")), bytes.HasPrefix(data, []byte("MM\x00*")):
+		info, err = tiffInfo(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		info, err = webpInfo(data)
+	case len(data) >= 12 && string(data[4:8]) == "ftyp" && heifBrands[string(data[8:12])]:
+		info, err = heifInfo(data)
+	default:
+		return ImageInfo{}, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return ImageInfo{}, err
+	}
+
+	if info.Width <= 0 || info.Height <= 0 {
+		return ImageInfo{}, fmt.Errorf("%w: %s is %dx%d", ErrEmptyImage, info.Format, info.Width, info.Height)
+	}
+	return info, nil
+}
+
+// validateImage 检查图片格式和尺寸，在调用引擎之前返回明确的错误。
+// maxPixels 为 0 时使用 DefaultMaxImagePixels，格式和边长的限制来自引擎的 caps。
+//
+// caps.RawInput 为 true 时无法识别的数据原样交给引擎，返回零值 ImageInfo；
+// 能够识别的图片仍然检查尺寸。
+func validateImage(data []byte, maxPixels int64, caps Capabilities) (ImageInfo, error) {
+	info, err := DetectImage(data)
+	if err != nil {
+		if caps.RawInput {
+			return ImageInfo{}, nil
+		}
+		return ImageInfo{}, err
+	}
+	if len(caps.Formats) > 0 && !slices.Contains(caps.Formats, info.Format) {
+		return ImageInfo{}, fmt.Errorf("%w: %s is not supported by this engine", ErrUnsupportedFormat, info.Format)
+	}
+
+	if err := checkPixels(info.Width, info.Height, maxPixels); err != nil {
+		return ImageInfo{}, err
+	}
+	if maxDimension := caps.MaxImageDimension; maxDimension > 0 && (info.Width > maxDimension || info.Height > maxDimension) {
+		return ImageInfo{}, fmt.Errorf("%w: %dx%d exceeds engine limit of %d pixels per side", ErrImageTooLarge, info.Width, info.Height, maxDimension)
+	}
+	return info, nil
+}
+
+// checkPixels 检查 width×height 是否超出 maxPixels，maxPixels 为 0 时使用 DefaultMaxImagePixels。
+// 以除法比较，边长很大时也不会溢出。
+func checkPixels(width, height int, maxPixels int64) error {
+	if maxPixels <= 0 {
+		maxPixels = DefaultMaxImagePixels
+	}
+	if width > 0 && int64(height) > maxPixels/int64(width) {
+		return fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrImageTooLarge, width, height, maxPixels)
+	}
+	return nil
+}
+
+// truncated 返回格式头不完整时的错误。
+func truncated(format ImageFormat) error {
+	return fmt.Errorf("%w: truncated %s header", ErrInvalidImage, format)
+}
+
+// pngInfo 从 IHDR 块读取尺寸。
+func pngInfo(data []byte) (ImageInfo, error) {
+	if len(data) < 24 || string(data[12:16]) != "IHDR" {
+		return ImageInfo{}, truncated(FormatPNG)
+	}
+	return ImageInfo{
+		Format: FormatPNG,
+		Width:  int(binary.BigEndian.Uint32(data[16:20])),
+		Height: int(binary.BigEndian.Uint32(data[20:24])),
+	}, nil
+}
+
+// gifInfo 从逻辑屏幕描述符读取尺寸。
+func gifInfo(data []byte) (ImageInfo, error) {
+	if len(data) < 10 {
+		return ImageInfo{}, truncated(FormatGIF)
+	}
+	return ImageInfo{
+		Format: FormatGIF,
+		Width:  int(binary.LittleEndian.Uint16(data[6:8])),
+		Height: int(binary.LittleEndian.Uint16(data[8:10])),
+	}, nil
+}
+
+// bmpInfo 从 DIB 头读取尺寸，高度为负表示自上而下存储。
+func bmpInfo(data []byte) (ImageInfo, error) {
+	if len(data) < 18 {
+		return ImageInfo{}, truncated(FormatBMP)
+	}
+	info := ImageInfo{Format: FormatBMP}
+	headerSize := binary.LittleEndian.Uint32(data[14:18])
+	if headerSize == 12 {
+		// BITMAPCOREHEADER
+		if len(data) < 22 {
+			return ImageInfo{}, truncated(FormatBMP)
+		}
+		info.Width = int(binary.LittleEndian.Uint16(data[18:20]))
+		info.Height = int(binary.LittleEndian.Uint16(data[20:22]))
+		return info, nil
+	}
+	if len(data) < 26 {
+		return ImageInfo{}, truncated(FormatBMP)
+	}
+	info.Width = int(int32(binary.LittleEndian.Uint32(data[18:22])))
+	info.Height = int(int32(binary.LittleEndian.Uint32(data[22:26])))
+	if info.Height < 0 {
+		info.Height = -info.Height
+	}
+	return info, nil
+}
+
+// jpegInfo 从第一个 SOF 段读取尺寸。
+func jpegInfo(data []byte) (ImageInfo, error) {
+	var info ImageInfo
+	err := walkJPEG(data, func(marker byte, segment []byte) (bool, error) {
+		// SOF0-SOF15，不包括 DHT (C4)、JPG (C8) 和 DAC (CC)
+		if marker < 0xc0 || marker > 0xcf || marker == 0xc4 || marker == 0xc8 || marker == 0xcc {
+			return false, nil
+		}
+		if len(segment) < 5 {
+			return false, truncated(FormatJPEG)
+		}
+		info = ImageInfo{
+			Format: FormatJPEG,
+			Height: int(binary.BigEndian.Uint16(segment[1:3])),
+			Width:  int(binary.BigEndian.Uint16(segment[3:5])),
+		}
+		return true, nil
+	})
+	if err != nil {
+		return ImageInfo{}, err
+	}
+	if info.Format == "" {
+		return ImageInfo{}, fmt.Errorf("%w: JPEG has no frame header", ErrInvalidImage)
+	}
+	return info, nil
+}
+
+// walkJPEG 依次对扫描数据 (SOS) 之前的每个 JPEG 段调用 fn，segment 不含长度字段。
+// fn 返回 true 时停止遍历。
+func walkJPEG(data []byte, fn func(marker byte, segment []byte) (bool, error)) error {
+	i := 2
+	for {
+		// 跳过段之间的填充字节
+		for i < len(data) && data[i] == 0xff {
+			i++
+		}
+		if i >= len(data) {
+			return truncated(FormatJPEG)
+		}
+		marker := data[i]
+		i++
+
+		switch {
+		case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd8):
+			// 没有长度字段的独立标记
+			continue
+		case marker == 0xd9 || marker == 0xda:
+			// EOI 或 SOS，之后不再有元数据段
+			return nil
+		}
+
+		if i+2 > len(data) {
+			return truncated(FormatJPEG)
+		}
+		length := int(binary.BigEndian.Uint16(data[i : i+2]))
+		if length < 2 {
+			return fmt.Errorf("%w: invalid JPEG segment length", ErrInvalidImage)
+		}
+		end := min(i+length, len(data))
+		stop, err := fn(marker, data[i+2:end])
+		if stop || err != nil {
+			return err
+		}
+		i += length
+	}
+}
+
+// tiffInfo 从第一个 IFD 的 ImageWidth (256) 和 ImageLength (257) 标签读取尺寸。
+func tiffInfo(data []byte) (ImageInfo, error) {
+	if len(data) < 8 {
+		return ImageInfo{}, truncated(FormatTIFF)
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if data[0] == 'M' {
+		order = binary.BigEndian
+	}
+
+	offset := int64(order.Uint32(data[4:8]))
+	if offset+2 > int64(len(data)) {
+		return ImageInfo{}, truncated(FormatTIFF)
+	}
+	count := int64(order.Uint16(data[offset : offset+2]))
+	entries := offset + 2
+	if entries+count*12 > int64(len(data)) {
+		return ImageInfo{}, truncated(FormatTIFF)
+	}
+
+	info := ImageInfo{Format: FormatTIFF}
+	for n := int64(0); n < count; n++ {
+		entry := data[entries+n*12 : entries+n*12+12]
+		tag := order.Uint16(entry[0:2])
+		if tag != 256 && tag != 257 {
+			continue
+		}
+
+		var value int
+		switch order.Uint16(entry[2:4]) {
+		case 3: // SHORT
+			value = int(order.Uint16(entry[8:10]))
+		case 4: // LONG
+			value = int(order.Uint32(entry[8:12]))
+		default:
+			return ImageInfo{}, fmt.Errorf("%w: unexpected TIFF dimension type", ErrInvalidImage)
+		}
+		if tag == 256 {
+			info.Width = value
+		} else {
+			info.Height = value
+		}
+	}
+	return info, nil
+}
+
+// webpInfo 根据第一个块的类型（VP8、VP8L、VP8X）读取尺寸。
+func webpInfo(data []byte) (ImageInfo, error) {
+	if len(data) < 20 {
+		return ImageInfo{}, truncated(FormatWebP)
+	}
+	chunk := data[20:]
+	info := ImageInfo{Format: FormatWebP}
+
+	switch string(data[12:16]) {
+	case "VP8 ":
+		// 帧标签 3 字节，起始码 9d 01 2a，随后是 14 位宽高
+		if len(chunk) < 10 {
+			return ImageInfo{}, truncated(FormatWebP)
+		}
+		if chunk[3] != 0x9d || chunk[4] != 0x01 || chunk[5] != 0x2a {
+			return ImageInfo{}, fmt.Errorf("%w: bad VP8 start code", ErrInvalidImage)
+		}
+		info.Width = int(binary.LittleEndian.Uint16(chunk[6:8]) & 0x3fff)
+		info.Height = int(binary.LittleEndian.Uint16(chunk[8:10]) & 0x3fff)
+	case "VP8L":
+		// 签名 0x2f，随后是 14 位 (宽-1) 和 14 位 (高-1)
+		if len(chunk) < 5 {
+			return ImageInfo{}, truncated(FormatWebP)
+		}
+		if chunk[0] != 0x2f {
+			return ImageInfo{}, fmt.Errorf("%w: bad VP8L signature", ErrInvalidImage)
+		}
+		bits := binary.LittleEndian.Uint32(chunk[1:5])
+		info.Width = int(bits&0x3fff) + 1
+		info.Height = int(bits>>14&0x3fff) + 1
+	case "VP8X":
+		// 4 字节标志，随后是 24 位 (宽-1) 和 24 位 (高-1)
+		if len(chunk) < 10 {
+			return ImageInfo{}, truncated(FormatWebP)
+		}
+		info.Width = int(uint32(chunk[4])|uint32(chunk[5])<<8|uint32(chunk[6])<<16) + 1
+		info.Height = int(uint32(chunk[7])|uint32(chunk[8])<<8|uint32(chunk[9])<<16) + 1
+	default:
+		return ImageInfo{}, fmt.Errorf("%w: unknown WebP chunk %q", ErrInvalidImage, data[12:16])
+	}
+	return info, nil
+}
+
+// heifBrands 是 HEIF 图片 ftyp 盒子中的主品牌。
+var heifBrands = map[string]bool{
+	"heic": true, "heix": true, "heim": true, "heis": true,
+	"hevc": true, "hevx": true, "mif1": true, "msf1": true,
+}
+
+// heifInfo 从 meta/iprp/ipco 中的 ispe 属性读取尺寸。
+// 文件中可能有多个 ispe（网格图片的分块、缩略图），取面积最大的一个作为主图片。
+// 返回的是存储方向的尺寸，不考虑 irot：引擎按存储方向解码 HEIF，坐标也基于存储方向。
+func heifInfo(data []byte) (ImageInfo, error) {
+	info := ImageInfo{Format: FormatHEIF}
+	walkBoxes(data, func(typ string, body []byte) {
+		if typ != "meta" || len(body) < 4 {
+			return
+		}
+		// meta 是 FullBox，跳过版本和标志
+		walkBoxes(body[4:], func(typ string, body []byte) {
+			if typ != "iprp" {
+				return
+			}
+			walkBoxes(body, func(typ string, body []byte) {
+				if typ != "ipco" {
+					return
+				}
+				walkBoxes(body, func(typ string, body []byte) {
+					if typ != "ispe" || len(body) < 12 {
+						return
+					}
+					w := int(binary.BigEndian.Uint32(body[4:8]))
+					h := int(binary.BigEndian.Uint32(body[8:12]))
+					if int64(w)*int64(h) > int64(info.Width)*int64(info.Height) {
+						info.Width, info.Height = w, h
+					}
+				})
+			})
+		})
+	})
+	if info.Width == 0 && info.Height == 0 {
+		return ImageInfo{}, fmt.Errorf("%w: HEIF has no image spatial extents", ErrInvalidImage)
+	}
+	return info, nil
+}
+
+// walkBoxes 依次对 data 中每个 ISO BMFF 盒子调用 fn，body 不含盒子头。遇到不完整的盒子时停止。
+func walkBoxes(data []byte, fn func(typ string, body []byte)) {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data[0:4]))
+		typ := string(data[4:8])
+		header := uint64(8)
+		switch size {
+		case 0:
+			// 延续到数据末尾
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return
+			}
+			size, header = binary.BigEndian.Uint64(data[8:16]), 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return
+		}
+		fn(typ, data[header:size])
+		data = data[size:]
+	}
+}
@@ -0,0 +1,163 @@
+package sysocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// isoBox 返回类型为 typ、内容为 body 的 ISO BMFF 盒子。
+func isoBox(typ string, body ...[]byte) []byte {
+	size := 8
+	for _, b := range body {
+		size += len(b)
+	}
+	box := binary.BigEndian.AppendUint32(nil, uint32(size))
+	box = append(box, typ...)
+	for _, b := range body {
+		box = append(box, b...)
+	}
+	return box
+}
+
+// ispe 返回 ispe 属性盒子。
+func ispe(w, h uint32) []byte {
+	body := make([]byte, 4, 12) // 版本和标志
+	body = binary.BigEndian.AppendUint32(body, w)
+	body = binary.BigEndian.AppendUint32(body, h)
+	return isoBox("ispe", body)
+}
+
+// testHEIF 返回只包含 ftyp 和 meta 盒子的 HEIF 文件头。
+func testHEIF(brand string, props ...[]byte) []byte {
+	ftyp := isoBox("ftyp", []byte(brand), make([]byte, 4), []byte("mif1heic"))
+	meta := isoBox("meta", make([]byte, 4), isoBox("hdlr", make([]byte, 24)),
+		isoBox("iprp", isoBox("ipco", props...)))
+	return append(ftyp, append(meta, isoBox("mdat", []byte("pixels"))...)...)
+}
+
+func TestDetectImageHEIF(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data []byte
+		w, h int
+	}{
+		{"heic", testHEIF("heic", ispe(4032, 3024)), 4032, 3024},
+		{"mif1", testHEIF("mif1", ispe(640, 480)), 640, 480},
+		// 网格图片：512×512 的分块、缩略图和完整尺寸
+		{"grid", testHEIF("heic", ispe(512, 512), ispe(320, 240), ispe(4032, 3024)), 4032, 3024},
+		// irot 不会被应用，尺寸是存储方向的尺寸
+		{"rotated", testHEIF("heic", ispe(4032, 3024), isoBox("irot", []byte{3})), 4032, 3024},
+		{"rotated 180", testHEIF("heic", ispe(4032, 3024), isoBox("irot", []byte{2})), 4032, 3024},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := DetectImage(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Format != FormatHEIF || info.Width != tt.w || info.Height != tt.h {
+				t.Fatalf("DetectImage = %+v, want heif %dx%d", info, tt.w, tt.h)
+			}
+		})
+	}
+}
+
+func TestDetectImageHEIFInvalid(t *testing.T) {
+	if _, err := DetectImage(testHEIF("heic")); !errors.Is(err, ErrInvalidImage) {
+		t.Errorf("HEIF without ispe: err = %v, want ErrInvalidImage", err)
+	}
+	// 截断的盒子不能导致越界访问
+	data := testHEIF("heic", ispe(10, 10))
+	for n := 12; n < len(data); n++ {
+		DetectImage(data[:n])
+	}
+	// AVIF 同样基于 ISO BMFF，但不是 HEIF
+	if _, err := DetectImage(testHEIF("avif", ispe(10, 10))); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("AVIF: err = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestDetectImage(t *testing.T) {
+	info, err := DetectImage(testPNG(t, 30, 20))
+	if err != nil || info != (ImageInfo{Format: FormatPNG, Width: 30, Height: 20}) {
+		t.Fatalf("DetectImage(png) = %+v, %v", info, err)
+	}
+	if _, err := DetectImage(nil); !errors.Is(err, ErrEmptyImage) {
+		t.Errorf("empty data: err = %v, want ErrEmptyImage", err)
+	}
+	if _, err := DetectImage([]byte("not an image")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("text data: err = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestValidateImage(t *testing.T) {
+	png := testPNG(t, 30, 20)
+	heif := testHEIF("heic", ispe(30, 20))
+	tesseractFormats := []ImageFormat{FormatPNG, FormatJPEG}
+
+	for _, tt := range []struct {
+		name      string
+		data      []byte
+		maxPixels int64
+		caps      Capabilities
+		wantErr   error
+	}{
+		{"png", png, 0, Capabilities{}, nil},
+		{"heif for any format", heif, 0, Capabilities{}, nil},
+		{"heif for limited formats", heif, 0, Capabilities{Formats: tesseractFormats}, ErrUnsupportedFormat},
+		{"png for limited formats", png, 0, Capabilities{Formats: tesseractFormats}, nil},
+		{"too many pixels", png, 599, Capabilities{}, ErrImageTooLarge},
+		{"engine dimension", png, 0, Capabilities{MaxImageDimension: 25}, ErrImageTooLarge},
+		{"placeholder", []byte("placeholder"), 0, Capabilities{}, ErrUnsupportedFormat},
+		{"placeholder raw", []byte("placeholder"), 0, Capabilities{RawInput: true}, nil},
+		{"raw still limited", png, 599, Capabilities{RawInput: true}, ErrImageTooLarge},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateImage(tt.data, tt.maxPixels, tt.caps)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFakeEnginePlaceholderInput(t *testing.T) {
+	registerTestEngine(t, &FakeEngine{
+		EngineName: "test-placeholder",
+		Result:     &Result{Blocks: []TextBlock{{Text: "hi", BoundingBox: BoundingBox{0.1, 0.1, 0.2, 0.1}}}},
+	})
+	result, err := Recognize(Options{Engine: "test-placeholder", Input: Input{Data: []byte("not an image")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Text != "hi" || result.ImageWidth != 0 || result.ImageHeight != 0 {
+		t.Fatalf("result = %+v", result)
+	}
+}
+
+func TestRecognizeRotatedHEIF(t *testing.T) {
+	// 竖拍的 iPhone 照片：存储为 4032×3024，irot 为 270°（逆时针 90°×3）
+	data := testHEIF("heic", ispe(4032, 3024), isoBox("irot", []byte{3}))
+	var got []byte
+	registerTestEngine(t, &FakeEngine{
+		EngineName: "test-heif",
+		Func: func(ctx context.Context, data []byte, opts EngineOptions) (*Result, error) {
+			got = data
+			return &Result{Blocks: []TextBlock{{Text: "a", BoundingBox: BoundingBox{0.5, 0.1, 0.4, 0.1}, Confidence: 1}}}, nil
+		},
+	})
+	result, err := Recognize(Options{Engine: "test-heif", Input: Input{Data: data}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 引擎收到未旋转的原始数据，尺寸和方向与其坐标所基于的存储方向一致
+	if !bytes.Equal(got, data) {
+		t.Fatal("engine did not receive the original HEIF data")
+	}
+	if result.ImageWidth != 4032 || result.ImageHeight != 3024 || result.Orientation != 0 {
+		t.Fatalf("result size = %dx%d, orientation %d, want 4032x3024 and 0",
+			result.ImageWidth, result.ImageHeight, result.Orientation)
+	}
+}
@@ -1,60 +1,186 @@
 package sysocr
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"image"
+	"image/png"
 	"io"
+	"io/fs"
+	"mime"
 	"net/http"
+	"net/netip"
 	"os"
 	"strings"
+	"sync"
 )
 
 var (
 	ErrNoInput       = errors.New("sysocr: no input specified")
 	ErrMultipleInput = errors.New("sysocr: multiple inputs specified, only one allowed")
+
+	ErrResponseTooLarge      = errors.New("sysocr: response body exceeds size limit")
+	ErrUnexpectedContentType = errors.New("sysocr: unexpected response content type")
 )
 
-// resolveInput 将 Input 转换为原始图片字节数据。
-func resolveInput(input Input) ([]byte, error) {
+// sourceCount 返回 Input 中设置的图片来源数量，FS 与 FilePath 的组合视为一个来源。
+func (input Input) sourceCount() int {
 	count := 0
-	if input.FilePath != "" {
-		count++
-	}
-	if input.URL != "" {
-		count++
-	}
-	if input.Data != nil {
-		count++
+	for _, set := range []bool{
+		input.FilePath != "",
+		input.URL != "",
+		input.Data != nil,
+		input.Reader != nil,
+		input.Image != nil,
+		input.Base64 != "",
+	} {
+		if set {
+			count++
+		}
 	}
+	return count
+}
 
+// resolveInput 将 Input 转换为原始图片字节数据。
+// Input.Image 在编码前按 maxPixels 检查尺寸，其他来源在 validateImage 中检查。
+func resolveInput(ctx context.Context, input Input, fetch FetchOptions, maxPixels int64) ([]byte, error) {
+	count := input.sourceCount()
 	if count == 0 {
+		if input.FS != nil {
+			return nil, fmt.Errorf("%w: Input.FS requires FilePath", ErrNoInput)
+		}
 		return nil, ErrNoInput
 	}
 	if count > 1 {
 		return nil, ErrMultipleInput
 	}
+	if input.FS != nil && input.FilePath == "" {
+		return nil, fmt.Errorf("%w: Input.FS can only be combined with FilePath", ErrMultipleInput)
+	}
 
 	if input.Data != nil {
 		return input.Data, nil
 	}
 
+	if input.Base64 != "" {
+		return decodeBase64(input.Base64)
+	}
+
+	if input.Image != nil {
+		// 过大的图片（如边界几乎无限的 image.Uniform）在编码时会耗尽内存
+		bounds := input.Image.Bounds()
+		if err := checkPixels(bounds.Dx(), bounds.Dy(), maxPixels); err != nil {
+			return nil, err
+		}
+		return encodeImage(input.Image)
+	}
+
+	if input.Reader != nil {
+		return io.ReadAll(&contextReader{ctx: ctx, r: input.Reader})
+	}
+
 	if input.FilePath != "" {
+		if input.FS != nil {
+			return fs.ReadFile(input.FS, input.FilePath)
+		}
 		return os.ReadFile(input.FilePath)
 	}
 
 	if input.URL != "" {
-		return fetchURL(input.URL)
+		if isDataURI(input.URL) {
+			return decodeDataURI(input.URL)
+		}
+		return fetchURL(ctx, input.URL, fetch)
 	}
 
 	return nil, ErrNoInput
 }
 
+// imageEncoder 以不压缩的 PNG 编码图片。
+// 编码结果只在本机传给后端解码，体积无关紧要，不压缩可以同时省去编码和解码的开销，
+// 而 PNG 是所有后端都支持的无损格式。
+var imageEncoder = &png.Encoder{
+	CompressionLevel: png.NoCompression,
+	BufferPool:       &pngBufferPool{},
+}
+
+// pngBufferPool 复用 PNG 编码器的内部缓冲区。
+type pngBufferPool struct {
+	pool sync.Pool
+}
+
+func (p *pngBufferPool) Get() *png.EncoderBuffer {
+	b, _ := p.pool.Get().(*png.EncoderBuffer)
+	return b
+}
+
+func (p *pngBufferPool) Put(b *png.EncoderBuffer) {
+	p.pool.Put(b)
+}
+
+// encodeImage 将 image.Image 编码为后端可以直接解码的字节数据。
+func encodeImage(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return nil, errors.New("sysocr: Input.Image is empty")
+	}
+
+	var buf bytes.Buffer
+	if err := imageEncoder.Encode(&buf, img); err != nil {
+		return nil, errors.New("sysocr: failed to encode Input.Image: " + err.Error())
+	}
+	return buf.Bytes(), nil
+}
+
+// contextReader 在每次读取前检查 ctx，使读取慢速数据流时也能响应取消。
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
 // fetchURL 从远程 URL 获取图片数据。
-func fetchURL(url string) ([]byte, error) {
+func fetchURL(ctx context.Context, url string, fetch FetchOptions) ([]byte, error) {
 	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
-		return nil, errors.New("sysocr: URL must start with http:// or https://")
+		return nil, errors.New("sysocr: URL must start with http://, https:// or data:")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range fetch.Header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+
+	client := fetch.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if fetch.Safe {
+		// 字面量 IP 可以在发起请求前直接拒绝
+		if addr, err := netip.ParseAddr(req.URL.Hostname()); err == nil && addrForbidden(addr, fetch) {
+			return nil, &ForbiddenURLError{Host: req.URL.Hostname(), Addr: addr.Unmap()}
+		}
+		safe, cleanup, err := safeClient(fetch)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		client = safe
 	}
 
-	resp, err := http.Get(url)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -64,5 +190,45 @@ func fetchURL(url string) ([]byte, error) {
 		return nil, errors.New("sysocr: failed to fetch URL: " + resp.Status)
 	}
 
-	return io.ReadAll(resp.Body)
+	if len(fetch.ContentTypes) > 0 {
+		contentType := resp.Header.Get("Content-Type")
+		if !contentTypeAllowed(contentType, fetch.ContentTypes) {
+			return nil, fmt.Errorf("%w: %q", ErrUnexpectedContentType, contentType)
+		}
+	}
+
+	if fetch.MaxBytes <= 0 {
+		return io.ReadAll(resp.Body)
+	}
+	if resp.ContentLength > fetch.MaxBytes {
+		return nil, fmt.Errorf("%w: %d > %d bytes", ErrResponseTooLarge, resp.ContentLength, fetch.MaxBytes)
+	}
+	// 多读一个字节以判断是否超出上限（Content-Length 可能缺失或不可信）
+	data, err := io.ReadAll(io.LimitReader(resp.Body, fetch.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > fetch.MaxBytes {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, fetch.MaxBytes)
+	}
+	return data, nil
+}
+
+// contentTypeAllowed 判断 Content-Type 是否在允许列表中，支持 "image/*" 形式的通配。
+func contentTypeAllowed(contentType string, allowed []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if mediaType == pattern {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,311 @@
+package sysocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFetchURLMaxBytes(t *testing.T) {
+	body := bytes.Repeat([]byte("x"), 100)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			// 先刷新响应头，使响应使用分块编码而不带 Content-Length
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+		}
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	for _, tt := range []struct {
+		name     string
+		path     string
+		maxBytes int64
+		wantErr  error
+	}{
+		{"no limit", "/", 0, nil},
+		{"at limit", "/", 100, nil},
+		{"over limit with Content-Length", "/", 99, ErrResponseTooLarge},
+		{"at limit without Content-Length", "/chunked", 100, nil},
+		{"over limit without Content-Length", "/chunked", 99, ErrResponseTooLarge},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := fetchURL(context.Background(), srv.URL+tt.path, FetchOptions{MaxBytes: tt.maxBytes})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !bytes.Equal(data, body) {
+				t.Fatalf("got %d bytes, want %d", len(data), len(body))
+			}
+		})
+	}
+}
+
+func TestFetchURLContentTypes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.URL.Query().Get("ct"); ct != "" {
+			w.Header().Set("Content-Type", ct)
+		}
+		w.Write([]byte("data"))
+	}))
+	defer srv.Close()
+
+	for _, tt := range []struct {
+		contentType string
+		allowed     []string
+		ok          bool
+	}{
+		{"image/png", nil, true},
+		{"text/html", nil, true},
+		{"image/png", []string{"image/png"}, true},
+		{"IMAGE/PNG; charset=binary", []string{"image/png"}, true},
+		{"image/jpeg", []string{"image/png"}, false},
+		{"image/webp", []string{"image/*"}, true},
+		{"image/jpeg", []string{" Image/* "}, true},
+		{"text/html", []string{"image/*"}, false},
+		{"imagex/png", []string{"image/*"}, false},
+		{"", []string{"image/*"}, false},
+	} {
+		fetch := FetchOptions{ContentTypes: tt.allowed}
+		_, err := fetchURL(context.Background(), srv.URL+"/?ct="+url.QueryEscape(tt.contentType), fetch)
+		if tt.ok && err != nil {
+			t.Errorf("%q allowed by %q: err = %v", tt.contentType, tt.allowed, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrUnexpectedContentType) {
+			t.Errorf("%q allowed by %q: err = %v, want ErrUnexpectedContentType", tt.contentType, tt.allowed, err)
+		}
+	}
+}
+
+func TestFetchURLHeader(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer token")
+	header.Set("User-Agent", "sysocr-test")
+	header.Add("X-Multi", "a")
+	header.Add("X-Multi", "b")
+	if _, err := fetchURL(context.Background(), srv.URL, FetchOptions{Header: header}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("Authorization") != "Bearer token" || got.Get("User-Agent") != "sysocr-test" {
+		t.Errorf("headers = %v", got)
+	}
+	if v := got.Values("X-Multi"); len(v) != 2 || v[0] != "a" || v[1] != "b" {
+		t.Errorf("X-Multi = %q, want [a b]", v)
+	}
+}
+
+func TestFetchURLClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("data"))
+	}))
+	defer srv.Close()
+
+	used := false
+	client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		used = true
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+	if _, err := fetchURL(context.Background(), srv.URL, FetchOptions{Client: client}); err != nil {
+		t.Fatal(err)
+	}
+	if !used {
+		t.Fatal("FetchOptions.Client was not used")
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestFetchURLStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/error":
+			http.Error(w, "boom", http.StatusInternalServerError)
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	for path, status := range map[string]string{"/missing": "404", "/error": "500", "/empty": "204"} {
+		_, err := fetchURL(context.Background(), srv.URL+path, FetchOptions{})
+		if err == nil || !strings.Contains(err.Error(), status) {
+			t.Errorf("GET %s: err = %v, want status %s", path, err, status)
+		}
+	}
+}
+
+func TestFetchURLScheme(t *testing.T) {
+	for _, u := range []string{"ftp://example.com/a.png", "file:///etc/passwd", "example.com/a.png"} {
+		if _, err := fetchURL(context.Background(), u, FetchOptions{}); err == nil {
+			t.Errorf("fetchURL(%q) succeeded", u)
+		}
+	}
+}
+
+func TestEncodeImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(10, 20, 13, 22)) // 原点不在 (0, 0)
+	img.Set(10, 20, color.NRGBA{255, 0, 0, 128})
+	img.Set(12, 21, color.NRGBA{0, 0, 255, 255})
+	data, err := encodeImage(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Bounds().Size() != img.Bounds().Size() {
+		t.Fatalf("decoded size = %v, want %v", decoded.Bounds().Size(), img.Bounds().Size())
+	}
+	// 编码是无损的，包括半透明像素
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			want := color.NRGBAModel.Convert(img.At(10+x, 20+y))
+			if got := color.NRGBAModel.Convert(decoded.At(x, y)); got != want {
+				t.Errorf("pixel (%d, %d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+
+	if _, err := encodeImage(image.NewGray(image.Rect(0, 0, 0, 5))); err == nil {
+		t.Error("encodeImage of an empty image succeeded")
+	}
+}
+
+// sizedImage 是指定边界的单色图片，用于构造很大的图片而不分配像素。
+type sizedImage struct {
+	*image.Uniform
+	bounds image.Rectangle
+}
+
+func (s sizedImage) Bounds() image.Rectangle { return s.bounds }
+
+func TestResolveInputImage(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name      string
+		img       image.Image
+		maxPixels int64
+		wantErr   error
+	}{
+		{"small", image.NewGray(image.Rect(0, 0, 4, 3)), 0, nil},
+		{"at limit", image.NewGray(image.Rect(0, 0, 4, 3)), 12, nil},
+		{"over limit", image.NewGray(image.Rect(0, 0, 4, 3)), 11, ErrImageTooLarge},
+		// image.Uniform 的边界约为 2e9×2e9
+		{"uniform", image.NewUniform(color.White), 0, ErrImageTooLarge},
+		// 宽×高超出 int64 时不能因溢出而通过检查
+		{"overflow", sizedImage{image.NewUniform(color.White), image.Rect(0, 0, math.MaxInt, math.MaxInt)}, math.MaxInt64, ErrImageTooLarge},
+		{"60000x60000", sizedImage{image.NewUniform(color.White), image.Rect(0, 0, 60000, 60000)}, 0, ErrImageTooLarge},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := resolveInput(ctx, Input{Image: tt.img}, FetchOptions{}, tt.maxPixels)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			info, err := DetectImage(data)
+			if err != nil || info.Format != FormatPNG || info.Width != 4 || info.Height != 3 {
+				t.Fatalf("DetectImage = %+v, %v", info, err)
+			}
+		})
+	}
+}
+
+func TestResolveInputSources(t *testing.T) {
+	ctx := context.Background()
+	img := []byte("image data")
+	fsys := fstest.MapFS{"images/a.png": {Data: img}}
+	for _, tt := range []struct {
+		name    string
+		input   Input
+		wantErr error
+	}{
+		{"data", Input{Data: img}, nil},
+		{"reader", Input{Reader: bytes.NewReader(img)}, nil},
+		{"base64", Input{Base64: base64.StdEncoding.EncodeToString(img)}, nil},
+		{"FS", Input{FS: fsys, FilePath: "images/a.png"}, nil},
+		{"none", Input{}, ErrNoInput},
+		{"FS without FilePath", Input{FS: fsys}, ErrNoInput},
+		{"FS with URL", Input{FS: fsys, URL: "https://example.com/a.png"}, ErrMultipleInput},
+		{"FS with Data", Input{FS: fsys, Data: img}, ErrMultipleInput},
+		{"reader with data", Input{Reader: bytes.NewReader(img), Data: img}, ErrMultipleInput},
+		{"path with URL", Input{FilePath: "a.png", URL: "https://example.com/a.png"}, ErrMultipleInput},
+		// 空切片也算设置了 Data
+		{"empty data with base64", Input{Data: []byte{}, Base64: "eA=="}, ErrMultipleInput},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := resolveInput(ctx, tt.input, FetchOptions{}, 0)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !bytes.Equal(data, img) {
+				t.Fatalf("data = %q, want %q", data, img)
+			}
+		})
+	}
+}
+
+// endlessReader 无限返回数据，并在第一次读取后调用 onRead。
+type endlessReader struct {
+	reads  int
+	onRead func()
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	r.reads++
+	if r.reads == 1 && r.onRead != nil {
+		r.onRead()
+	}
+	for i := range p {
+		p[i] = 'x'
+	}
+	return len(p), nil
+}
+
+func TestResolveInputReaderCanceled(t *testing.T) {
+	// 已取消的 ctx 不读取数据
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := &endlessReader{}
+	if _, err := resolveInput(ctx, Input{Reader: r}, FetchOptions{}, 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if r.reads != 0 {
+		t.Fatalf("read %d times after cancellation", r.reads)
+	}
+
+	// 读取过程中取消时停止读取不会结束的数据流
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	r = &endlessReader{onRead: cancel}
+	if _, err := resolveInput(ctx, Input{Reader: r}, FetchOptions{}, 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if r.reads != 1 {
+		t.Fatalf("read %d times, want 1", r.reads)
+	}
+}
@@ -17,6 +17,25 @@ import (
 
 // TextBlock 表示识别到的文本块及其边界框。
 type TextBlock struct {
+	Text       string
+	X          float64
+	Y          float64
+	Width      float64
+	Height     float64
+	Confidence float64    // 0-1，未知时为 -1
+	Quad       [8]float64 // 左上、右上、右下、左下四个角的 x, y
+	Words      []Word
+	Candidates []Candidate
+}
+
+// Candidate 表示一个候选识别结果。
+type Candidate struct {
+	Text       string
+	Confidence float64
+}
+
+// Word 表示行内的单词及其边界框。
+type Word struct {
 	Text   string
 	X      float64
 	Y      float64
@@ -30,7 +49,8 @@ type Result struct {
 }
 
 // Recognize 使用 macOS Vision Framework 对图片数据进行 OCR 识别。
-func Recognize(data []byte, languages []string) (*Result, error) {
+// maxCandidates 大于 0 时，每行最多返回 maxCandidates 个候选结果（Vision 上限为 10）。
+func Recognize(data []byte, languages []string, maxCandidates int) (*Result, error) {
 	if len(data) == 0 {
 		return nil, errors.New("empty image data")
 	}
@@ -57,7 +77,7 @@ func Recognize(data []byte, languages []string) (*Result, error) {
 	}
 
 	// 调用 C 函数
-	cResult := C.sysocr_recognize(dataPtr, dataLen, langsPtr, langsCount)
+	cResult := C.sysocr_recognize(dataPtr, dataLen, langsPtr, langsCount, C.int(maxCandidates))
 	defer C.sysocr_free_result(cResult)
 
 	// 检查错误
@@ -74,11 +94,38 @@ func Recognize(data []byte, languages []string) (*Result, error) {
 		blocks := unsafe.Slice(cResult.blocks, int(cResult.count))
 		for i, b := range blocks {
 			result.Blocks[i] = TextBlock{
-				Text:   C.GoString(b.text),
-				X:      float64(b.x),
-				Y:      float64(b.y),
-				Width:  float64(b.width),
-				Height: float64(b.height),
+				Text:       C.GoString(b.text),
+				X:          float64(b.x),
+				Y:          float64(b.y),
+				Width:      float64(b.width),
+				Height:     float64(b.height),
+				Confidence: float64(b.confidence),
+			}
+			for j := range result.Blocks[i].Quad {
+				result.Blocks[i].Quad[j] = float64(b.quad[j])
+			}
+			if b.word_count > 0 && b.words != nil {
+				words := unsafe.Slice(b.words, int(b.word_count))
+				result.Blocks[i].Words = make([]Word, len(words))
+				for j, w := range words {
+					result.Blocks[i].Words[j] = Word{
+						Text:   C.GoString(w.text),
+						X:      float64(w.x),
+						Y:      float64(w.y),
+						Width:  float64(w.width),
+						Height: float64(w.height),
+					}
+				}
+			}
+			if b.candidate_count > 0 && b.candidates != nil {
+				candidates := unsafe.Slice(b.candidates, int(b.candidate_count))
+				result.Blocks[i].Candidates = make([]Candidate, len(candidates))
+				for j, c := range candidates {
+					result.Blocks[i].Candidates[j] = Candidate{
+						Text:       C.GoString(c.text),
+						Confidence: float64(c.confidence),
+					}
+				}
 			}
 		}
 	}
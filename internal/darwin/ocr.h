@@ -1,6 +1,21 @@
 #ifndef SYSOCR_DARWIN_H
 #define SYSOCR_DARWIN_H
 
+// OCRWord 表示行内的单个单词及其位置信息
+typedef struct {
+    char* text;
+    double x;
+    double y;
+    double width;
+    double height;
+} OCRWord;
+
+// OCRCandidate 表示一个候选识别结果
+typedef struct {
+    char* text;
+    double confidence;
+} OCRCandidate;
+
 // OCRTextBlock 表示单个文本块及其位置信息
 typedef struct {
     char* text;
@@ -8,6 +23,12 @@ typedef struct {
     double y;
     double width;
     double height;
+    double confidence; // 0-1，没有候选文本时为 -1
+    double quad[8];    // 左上、右上、右下、左下四个角的 x, y（原点在左上角）
+    OCRWord* words;
+    int word_count;
+    OCRCandidate* candidates;
+    int candidate_count;
 } OCRTextBlock;
 
 // OCRResult 表示 OCR 识别结果
@@ -22,7 +43,8 @@ typedef struct {
 // length: 数据长度
 // languages: 语言提示数组
 // lang_count: 语言数量
-OCRResult sysocr_recognize(const unsigned char* data, int length, const char** languages, int lang_count);
+// max_candidates: 每行返回的候选结果数量，0 表示不返回
+OCRResult sysocr_recognize(const unsigned char* data, int length, const char** languages, int lang_count, int max_candidates);
 
 // sysocr_free_result 释放 OCRResult 占用的内存
 void sysocr_free_result(OCRResult result);
@@ -6,7 +6,60 @@
 #include <stdlib.h>
 #include <string.h>
 
-OCRResult sysocr_recognize(const unsigned char* data, int length, const char** languages, int lang_count) {
+// 将 Vision 的归一化坐标（原点在左下角）转换为原点在左上角
+static void sysocr_set_rect(CGRect bbox, double *x, double *y, double *width, double *height) {
+    *x = bbox.origin.x;
+    *y = 1.0 - bbox.origin.y - bbox.size.height;
+    *width = bbox.size.width;
+    *height = bbox.size.height;
+}
+
+// sysocr_set_point 将 Vision 的归一化点转换为原点在左上角并写入 out[0..1]
+static void sysocr_set_point(CGPoint p, double *out) {
+    out[0] = p.x;
+    out[1] = 1.0 - p.y;
+}
+
+// sysocr_fill_words 按单词切分候选文本，并通过 boundingBoxForRange 获取每个单词的边界框
+static void sysocr_fill_words(VNRecognizedText *candidate, OCRTextBlock *block) {
+    block->words = NULL;
+    block->word_count = 0;
+    if (candidate == nil) {
+        return;
+    }
+
+    NSString *text = candidate.string;
+    NSMutableArray<NSValue *> *ranges = [NSMutableArray array];
+    [text enumerateSubstringsInRange:NSMakeRange(0, text.length)
+                             options:NSStringEnumerationByWords | NSStringEnumerationSubstringNotRequired
+                          usingBlock:^(NSString *substring, NSRange substringRange, NSRange enclosingRange, BOOL *stop) {
+        [ranges addObject:[NSValue valueWithRange:substringRange]];
+    }];
+    if (ranges.count == 0) {
+        return;
+    }
+
+    block->words = (OCRWord *)calloc(ranges.count, sizeof(OCRWord));
+    if (block->words == NULL) {
+        return;
+    }
+
+    int count = 0;
+    for (NSValue *value in ranges) {
+        NSRange range = value.rangeValue;
+        NSError *error = nil;
+        VNRectangleObservation *wordObs = [candidate boundingBoxForRange:range error:&error];
+        if (wordObs == nil || error != nil) {
+            continue;
+        }
+        OCRWord *word = &block->words[count++];
+        word->text = strdup([[text substringWithRange:range] UTF8String]);
+        sysocr_set_rect(wordObs.boundingBox, &word->x, &word->y, &word->width, &word->height);
+    }
+    block->word_count = count;
+}
+
+OCRResult sysocr_recognize(const unsigned char* data, int length, const char** languages, int lang_count, int max_candidates) {
     OCRResult result = {0};
 
     @autoreleasepool {
@@ -86,7 +139,7 @@ OCRResult sysocr_recognize(const unsigned char* data, int length, const char** l
             return result;
         }
 
-        result.blocks = (OCRTextBlock *)malloc(sizeof(OCRTextBlock) * count);
+        result.blocks = (OCRTextBlock *)calloc(count, sizeof(OCRTextBlock));
         if (result.blocks == NULL) {
             result.error = strdup("failed to allocate memory");
             return result;
@@ -95,19 +148,40 @@ OCRResult sysocr_recognize(const unsigned char* data, int length, const char** l
 
         for (NSUInteger i = 0; i < count; i++) {
             VNRecognizedTextObservation *obs = observations[i];
-            VNRecognizedText *topCandidate = [[obs topCandidates:1] firstObject];
+            // Vision 最多返回 10 个候选结果
+            NSUInteger wanted = max_candidates > 1 ? (NSUInteger)MIN(max_candidates, 10) : 1;
+            NSArray<VNRecognizedText *> *candidates = [obs topCandidates:wanted];
+            VNRecognizedText *topCandidate = [candidates firstObject];
 
             // 获取文本
             NSString *text = topCandidate ? topCandidate.string : @"";
             result.blocks[i].text = strdup([text UTF8String]);
+            result.blocks[i].confidence = topCandidate ? topCandidate.confidence : -1.0;
 
             // 获取边界框（Vision 使用归一化坐标，原点在左下角）
-            CGRect bbox = obs.boundingBox;
-            result.blocks[i].x = bbox.origin.x;
-            // 从左下角原点转换为左上角原点
-            result.blocks[i].y = 1.0 - bbox.origin.y - bbox.size.height;
-            result.blocks[i].width = bbox.size.width;
-            result.blocks[i].height = bbox.size.height;
+            OCRTextBlock *block = &result.blocks[i];
+            sysocr_set_rect(obs.boundingBox, &block->x, &block->y, &block->width, &block->height);
+
+            // 获取四个角点，倾斜文本的四边形比 boundingBox 更精确
+            sysocr_set_point(obs.topLeft, &block->quad[0]);
+            sysocr_set_point(obs.topRight, &block->quad[2]);
+            sysocr_set_point(obs.bottomRight, &block->quad[4]);
+            sysocr_set_point(obs.bottomLeft, &block->quad[6]);
+
+            // 获取单词级别的边界框
+            sysocr_fill_words(topCandidate, block);
+
+            // 获取候选结果（第一个即 topCandidate）
+            if (max_candidates > 0 && candidates.count > 0) {
+                block->candidates = (OCRCandidate *)calloc(candidates.count, sizeof(OCRCandidate));
+                if (block->candidates != NULL) {
+                    for (NSUInteger j = 0; j < candidates.count; j++) {
+                        block->candidates[j].text = strdup([candidates[j].string UTF8String]);
+                        block->candidates[j].confidence = candidates[j].confidence;
+                    }
+                    block->candidate_count = (int)candidates.count;
+                }
+            }
         }
     }
 
@@ -118,6 +192,14 @@ void sysocr_free_result(OCRResult result) {
     if (result.blocks != NULL) {
         for (int i = 0; i < result.count; i++) {
             free(result.blocks[i].text);
+            for (int j = 0; j < result.blocks[i].word_count; j++) {
+                free(result.blocks[i].words[j].text);
+            }
+            free(result.blocks[i].words);
+            for (int j = 0; j < result.blocks[i].candidate_count; j++) {
+                free(result.blocks[i].candidates[j].text);
+            }
+            free(result.blocks[i].candidates);
         }
         free(result.blocks);
     }
@@ -3,6 +3,7 @@
 package winrt
 
 import (
+	"context"
 	"syscall"
 	"time"
 	"unsafe"
@@ -61,8 +62,13 @@ func (v *IAsyncInfo) GetStatus() AsyncStatus {
 	return result
 }
 
-// Wait 等待异步操作完成
-func (op *IAsyncOperation) Wait(timeout time.Duration) error {
+// Cancel 请求取消异步操作
+func (v *IAsyncInfo) Cancel() {
+	syscall.SyscallN(v.VTable().Cancel, uintptr(unsafe.Pointer(v)))
+}
+
+// Wait 等待异步操作完成，ctx 结束时取消操作并返回 ctx.Err()
+func (op *IAsyncOperation) Wait(ctx context.Context) error {
 	// 获取 IAsyncInfo 接口
 	var asyncInfo *IAsyncInfo
 	var ptr unsafe.Pointer
@@ -74,19 +80,25 @@ func (op *IAsyncOperation) Wait(timeout time.Duration) error {
 	defer asyncInfo.Release()
 
 	// 轮询等待完成
-	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
 	for {
 		status := asyncInfo.GetStatus()
 		if status != AsyncStatus_Started {
-			if status == AsyncStatus_Error {
+			switch status {
+			case AsyncStatus_Error:
 				return syscall.Errno(0x80004005) // E_FAIL
+			case AsyncStatus_Canceled:
+				return syscall.Errno(0x800704C7) // ERROR_CANCELLED
 			}
 			return nil
 		}
-		if time.Now().After(deadline) {
-			return syscall.ETIMEDOUT
+		select {
+		case <-ctx.Done():
+			asyncInfo.Cancel()
+			return ctx.Err()
+		case <-ticker.C:
 		}
-		time.Sleep(10 * time.Millisecond)
 	}
 }
 
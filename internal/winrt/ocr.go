@@ -109,6 +109,33 @@ func (v *IOcrResult) GetText() string {
 	return result
 }
 
+// IReferenceDouble 是 IReference<double> 接口（可为空的 double）
+type IReferenceDouble struct {
+	IInspectable
+}
+
+type IReferenceDoubleVtbl struct {
+	IInspectableVtbl
+	Get_Value uintptr
+}
+
+func (v *IReferenceDouble) VTable() *IReferenceDoubleVtbl {
+	return (*IReferenceDoubleVtbl)(unsafe.Pointer(v.Vtbl))
+}
+
+// GetTextAngle 返回文本的顺时针倾斜角度（度），引擎未检测到角度时 ok 为 false
+func (v *IOcrResult) GetTextAngle() (angle float64, ok bool) {
+	var ref *IReferenceDouble
+	hr, _, _ := syscall.SyscallN(v.VTable().Get_TextAngle, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&ref)))
+	if hr != 0 || ref == nil {
+		return 0, false
+	}
+	defer ref.Release()
+
+	hr, _, _ = syscall.SyscallN(ref.VTable().Get_Value, uintptr(unsafe.Pointer(ref)), uintptr(unsafe.Pointer(&angle)))
+	return angle, hr == 0
+}
+
 // GetLines 返回 IOcrLine 集合
 func (v *IOcrResult) GetLines() (*IVectorView, error) {
 	var result *IVectorView
@@ -169,6 +196,13 @@ func (v *IOcrEngineStatics) VTable() *IOcrEngineStaticsVtbl {
 	return (*IOcrEngineStaticsVtbl)(unsafe.Pointer(v.Vtbl))
 }
 
+// GetMaxImageDimension 返回 OCR 引擎支持的最大图片边长
+func (v *IOcrEngineStatics) GetMaxImageDimension() uint32 {
+	var result uint32
+	syscall.SyscallN(v.VTable().Get_MaxImageDimension, uintptr(unsafe.Pointer(v)), uintptr(unsafe.Pointer(&result)))
+	return result
+}
+
 // TryCreateFromUserProfileLanguages 创建使用用户配置语言的 OCR 引擎
 func (v *IOcrEngineStatics) TryCreateFromUserProfileLanguages() (*IOcrEngine, error) {
 	var result *IOcrEngine
@@ -0,0 +1,245 @@
+package sysocr
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// SchemaVersion1 是 Result 当前的 JSON 表示版本。
+const SchemaVersion1 = 1
+
+// ResultJSONSchema 是 Result JSON 表示的 JSON Schema（draft 2020-12），与 schema/result.schema.json 相同。
+//
+//go:embed schema/result.schema.json
+var ResultJSONSchema string
+
+var (
+	// ErrInvalidResult 表示 JSON 中的识别结果不符合 schema，如坐标越界或置信度超出范围。
+	ErrInvalidResult = errors.New("sysocr: invalid result")
+	// ErrUnsupportedSchemaVersion 表示 JSON 的 schema_version 缺失或比当前版本新。
+	ErrUnsupportedSchemaVersion = errors.New("sysocr: unsupported result schema version")
+)
+
+// boxMargin 是校验坐标时允许超出 0-1 的范围，引擎对贴近图片边缘的文本可能给出略微越界的边界框。
+const boxMargin = 0.05
+
+// UnmarshalResult 解析 data 中的一个 JSON 识别结果并校验其内容。
+// 与 json.Unmarshal 不同，未知字段、缺失或为 null 的必需字段、多余的数据和不符合 schema 的值都会返回错误，
+// 并会像 Recognize 一样重新分析得到 Result.Page。
+func UnmarshalResult(data []byte) (*Result, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	r, err := decodeResult(dec)
+	if err == io.EOF {
+		return nil, fmt.Errorf("sysocr: decode result: %w", io.ErrUnexpectedEOF)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("sysocr: decode result: unexpected data after result")
+	}
+	return r, nil
+}
+
+// decodeResult 从 dec 读取并校验下一个识别结果，没有更多数据时返回 io.EOF。
+func decodeResult(dec *json.Decoder) (*Result, error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, fmt.Errorf("sysocr: decode result: %w", err)
+	}
+	strict := json.NewDecoder(bytes.NewReader(raw))
+	strict.DisallowUnknownFields()
+	var r Result
+	if err := strict.Decode(&r); err != nil {
+		return nil, fmt.Errorf("sysocr: decode result: %w", err)
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	// encoding/json 对缺失的字段和 null 保留零值，必需字段需要在原始 JSON 上检查
+	if err := checkRequired(raw); err != nil {
+		return nil, err
+	}
+	r.Page = AnalyzeLayout(r.Blocks, r.ImageWidth, r.ImageHeight)
+	return &r, nil
+}
+
+// Validate 检查识别结果是否符合 ResultJSONSchema：版本号、图片尺寸、方向、
+// 边界框和 Quad 的坐标（允许少量越界）以及置信度（0-1 或 UnknownConfidence）。
+// 字段是否缺失只能在解码时检查，由 UnmarshalResult 和 JSONLDecoder 负责。
+func (r *Result) Validate() error {
+	if r.SchemaVersion < 1 || r.SchemaVersion > SchemaVersion1 {
+		return fmt.Errorf("%w: %d", ErrUnsupportedSchemaVersion, r.SchemaVersion)
+	}
+	if r.ImageWidth < 0 || r.ImageHeight < 0 {
+		return fmt.Errorf("%w: negative image size", ErrInvalidResult)
+	}
+	if r.Orientation < 0 || r.Orientation > OrientationRotate270 {
+		return fmt.Errorf("%w: orientation %d", ErrInvalidResult, r.Orientation)
+	}
+	if !finite(r.TextAngle) {
+		return fmt.Errorf("%w: text_angle is not finite", ErrInvalidResult)
+	}
+	for i, b := range r.Blocks {
+		if err := validateBox(b.BoundingBox); err != nil {
+			return fmt.Errorf("%w: blocks[%d].bounding_box: %v", ErrInvalidResult, i, err)
+		}
+		for _, p := range b.Quad {
+			if !finite(p.X) || !finite(p.Y) {
+				return fmt.Errorf("%w: blocks[%d].quad is not finite", ErrInvalidResult, i)
+			}
+			if p.X < -boxMargin || p.Y < -boxMargin || p.X > 1+boxMargin || p.Y > 1+boxMargin {
+				return fmt.Errorf("%w: blocks[%d].quad is outside of the image", ErrInvalidResult, i)
+			}
+		}
+		if !validConfidence(b.Confidence) {
+			return fmt.Errorf("%w: blocks[%d].confidence %v", ErrInvalidResult, i, b.Confidence)
+		}
+		for j, w := range b.Words {
+			if err := validateBox(w.BoundingBox); err != nil {
+				return fmt.Errorf("%w: blocks[%d].words[%d].bounding_box: %v", ErrInvalidResult, i, j, err)
+			}
+			if !validConfidence(w.Confidence) {
+				return fmt.Errorf("%w: blocks[%d].words[%d].confidence %v", ErrInvalidResult, i, j, w.Confidence)
+			}
+		}
+		for j, c := range b.Candidates {
+			if !validConfidence(c.Confidence) {
+				return fmt.Errorf("%w: blocks[%d].candidates[%d].confidence %v", ErrInvalidResult, i, j, c.Confidence)
+			}
+		}
+	}
+	return nil
+}
+
+// checkRequired 检查 ResultJSONSchema 中的必需字段是否都存在且不为 null（blocks 可以为 null），
+// 以及 quad 是否恰好有 4 个点。
+func checkRequired(raw json.RawMessage) error {
+	obj, err := requireFields(raw, "result", "schema_version", "text", "text_angle", "image_width", "image_height")
+	if err != nil {
+		return err
+	}
+	if _, ok := obj["blocks"]; !ok {
+		return fmt.Errorf("%w: result is missing \"blocks\"", ErrInvalidResult)
+	}
+	var blocks []json.RawMessage
+	json.Unmarshal(obj["blocks"], &blocks)
+	for i, rb := range blocks {
+		path := fmt.Sprintf("blocks[%d]", i)
+		block, err := requireFields(rb, path, "text", "bounding_box", "quad", "confidence")
+		if err != nil {
+			return err
+		}
+		if _, err := requireFields(block["bounding_box"], path+".bounding_box", "x", "y", "width", "height"); err != nil {
+			return err
+		}
+		var quad []json.RawMessage
+		if json.Unmarshal(block["quad"], &quad); len(quad) != len(Quad{}) {
+			return fmt.Errorf("%w: %s.quad must have 4 points", ErrInvalidResult, path)
+		}
+		for j, p := range quad {
+			if _, err := requireFields(p, fmt.Sprintf("%s.quad[%d]", path, j), "x", "y"); err != nil {
+				return err
+			}
+		}
+		var words, candidates []json.RawMessage
+		json.Unmarshal(block["words"], &words)
+		for j, rw := range words {
+			wpath := fmt.Sprintf("%s.words[%d]", path, j)
+			word, err := requireFields(rw, wpath, "text", "bounding_box", "confidence")
+			if err != nil {
+				return err
+			}
+			if _, err := requireFields(word["bounding_box"], wpath+".bounding_box", "x", "y", "width", "height"); err != nil {
+				return err
+			}
+		}
+		json.Unmarshal(block["candidates"], &candidates)
+		for j, rc := range candidates {
+			if _, err := requireFields(rc, fmt.Sprintf("%s.candidates[%d]", path, j), "text", "confidence"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// requireFields 将 raw 解析为 JSON 对象并检查 keys 中的字段都存在且不为 null。
+func requireFields(raw json.RawMessage, path string, keys ...string) (map[string]json.RawMessage, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil || obj == nil {
+		return nil, fmt.Errorf("%w: %s is not an object", ErrInvalidResult, path)
+	}
+	for _, k := range keys {
+		if v, ok := obj[k]; !ok || string(v) == "null" {
+			return nil, fmt.Errorf("%w: %s is missing %q", ErrInvalidResult, path, k)
+		}
+	}
+	return obj, nil
+}
+
+func validateBox(b BoundingBox) error {
+	if !finite(b.X) || !finite(b.Y) || !finite(b.Width) || !finite(b.Height) {
+		return errors.New("not finite")
+	}
+	if b.Width < 0 || b.Height < 0 {
+		return errors.New("negative size")
+	}
+	if b.X < -boxMargin || b.Y < -boxMargin || b.X+b.Width > 1+boxMargin || b.Y+b.Height > 1+boxMargin {
+		return errors.New("outside of the image")
+	}
+	return nil
+}
+
+func validConfidence(c float64) bool {
+	return c == UnknownConfidence || (c >= 0 && c <= 1)
+}
+
+func finite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// JSONLEncoder 以 JSON Lines 格式逐个写出识别结果，每行一个 Result，适用于批量处理的输出。
+type JSONLEncoder struct {
+	enc *json.Encoder
+}
+
+// NewJSONLEncoder 返回写入 w 的 JSONLEncoder。
+func NewJSONLEncoder(w io.Writer) *JSONLEncoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &JSONLEncoder{enc: enc}
+}
+
+// Encode 写出一个识别结果及换行符。SchemaVersion 为 0 时按当前版本写出。
+func (e *JSONLEncoder) Encode(r *Result) error {
+	if r.SchemaVersion == 0 {
+		v := *r
+		v.SchemaVersion = SchemaVersion1
+		r = &v
+	}
+	return e.enc.Encode(r)
+}
+
+// JSONLDecoder 从 JSON Lines 数据中逐个读取并校验识别结果。
+type JSONLDecoder struct {
+	dec *json.Decoder
+}
+
+// NewJSONLDecoder 返回从 r 读取的 JSONLDecoder。
+func NewJSONLDecoder(r io.Reader) *JSONLDecoder {
+	return &JSONLDecoder{dec: json.NewDecoder(r)}
+}
+
+// Decode 读取下一个识别结果，校验规则与 UnmarshalResult 相同。没有更多数据时返回 io.EOF。
+func (d *JSONLDecoder) Decode() (*Result, error) {
+	return decodeResult(d.dec)
+}
@@ -0,0 +1,200 @@
+package sysocr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// validResultJSON 是包含全部字段的最小合法结果，测试中通过替换其中的片段构造非法输入。
+const validResultJSON = `{"schema_version": 1, "blocks": [{
+	"text": "hi",
+	"bounding_box": {"x": 0.1, "y": 0.1, "width": 0.2, "height": 0.1},
+	"quad": [{"x": 0.1, "y": 0.1}, {"x": 0.3, "y": 0.1}, {"x": 0.3, "y": 0.2}, {"x": 0.1, "y": 0.2}],
+	"words": [{"text": "hi", "bounding_box": {"x": 0.1, "y": 0.1, "width": 0.2, "height": 0.1}, "confidence": 0.9}],
+	"confidence": 0.9,
+	"candidates": [{"text": "hi", "confidence": 0.9}, {"text": "hl", "confidence": -1}]
+}], "text": "hi", "text_angle": 0, "image_width": 100, "image_height": 50}`
+
+func TestUnmarshalResult(t *testing.T) {
+	r, err := UnmarshalResult([]byte(validResultJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Text != "hi" || len(r.Blocks) != 1 || len(r.Blocks[0].Candidates) != 2 || r.Page == nil {
+		t.Fatalf("result = %+v", r)
+	}
+
+	// Marshal 的输出可以原样解码
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UnmarshalResult(data); err != nil {
+		t.Fatalf("decode marshaled result: %v\n%s", err, data)
+	}
+
+	// 没有识别到文本时 blocks 为 null
+	empty, err := json.Marshal(&Result{SchemaVersion: SchemaVersion1, ImageWidth: 10, ImageHeight: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UnmarshalResult(empty); err != nil {
+		t.Fatalf("decode empty result: %v\n%s", err, empty)
+	}
+}
+
+func TestUnmarshalResultInvalid(t *testing.T) {
+	replace := func(old, new string) string {
+		if !strings.Contains(validResultJSON, old) {
+			t.Fatalf("fixture does not contain %q", old)
+		}
+		return strings.Replace(validResultJSON, old, new, 1)
+	}
+	for _, tt := range []struct {
+		name    string
+		data    string
+		wantErr error
+	}{
+		{"only version", `{"schema_version":1}`, ErrInvalidResult},
+		{"no version", replace(`"schema_version": 1, `, ""), ErrUnsupportedSchemaVersion},
+		{"newer version", replace(`"schema_version": 1`, `"schema_version": 2`), ErrUnsupportedSchemaVersion},
+		{"no blocks", replace(`"blocks": [`, `"b": [`), nil},
+		{"missing blocks", `{"schema_version": 1, "text": "", "text_angle": 0, "image_width": 1, "image_height": 1}`, ErrInvalidResult},
+		{"missing text", replace(`"text": "hi", "text_angle"`, `"text_angle"`), ErrInvalidResult},
+		{"missing text_angle", replace(`"text_angle": 0, `, ""), ErrInvalidResult},
+		{"missing image_width", replace(`"image_width": 100, `, ""), ErrInvalidResult},
+		{"missing image_height", replace(`, "image_height": 50`, ""), ErrInvalidResult},
+		{"null text", replace(`"text": "hi", "text_angle"`, `"text": null, "text_angle"`), ErrInvalidResult},
+		{"null text_angle", replace(`"text_angle": 0`, `"text_angle":  null`), ErrInvalidResult},
+		{"missing block text", replace("\t\"text\": \"hi\",\n", ""), ErrInvalidResult},
+		{"missing block confidence", replace("\t\"confidence\": 0.9,\n", ""), ErrInvalidResult},
+		{"missing quad", replace(`"quad"`, `"q"`), nil},
+		{"missing box field", replace(`{"x": 0.1, "y": 0.1, "width": 0.2, "height": 0.1},
+	"quad"`, `{"x": 0.1, "y": 0.1, "width": 0.2},
+	"quad"`), ErrInvalidResult},
+		{"missing word confidence", replace(`"height": 0.1}, "confidence": 0.9}]`, `"height": 0.1}}]`), ErrInvalidResult},
+		{"missing candidate confidence", replace(`{"text": "hl", "confidence": -1}`, `{"text": "hl"}`), ErrInvalidResult},
+		{"null quad", replace(`"quad": [{"x": 0.1, "y": 0.1}, {"x": 0.3, "y": 0.1}, {"x": 0.3, "y": 0.2}, {"x": 0.1, "y": 0.2}]`, `"quad": null`), ErrInvalidResult},
+		{"quad with 3 points", replace(`, {"x": 0.1, "y": 0.2}]`, `]`), ErrInvalidResult},
+		{"quad with 5 points", replace(`{"x": 0.1, "y": 0.2}]`, `{"x": 0.1, "y": 0.2}, {"x": 0.1, "y": 0.2}]`), ErrInvalidResult},
+		{"quad point missing y", replace(`{"x": 0.3, "y": 0.2}`, `{"x": 0.3}`), ErrInvalidResult},
+		{"quad outside", replace(`{"x": 0.3, "y": 0.2}`, `{"x": 1e6, "y": 0.2}`), ErrInvalidResult},
+		{"quad negative", replace(`{"x": 0.3, "y": 0.2}`, `{"x": 0.3, "y": -0.5}`), ErrInvalidResult},
+		{"box outside", replace(`"bounding_box": {"x": 0.1`, `"bounding_box": {"x": 1.1`), ErrInvalidResult},
+		{"confidence", replace(`"confidence": 0.9,`, `"confidence": 1.5,`), ErrInvalidResult},
+		{"orientation", replace(`"image_height": 50`, `"image_height": 50, "orientation": 9`), ErrInvalidResult},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := UnmarshalResult([]byte(tt.data))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnmarshalResultQuadMargin(t *testing.T) {
+	// 与边界框一样，贴近边缘的四边形允许略微越界
+	data := strings.Replace(validResultJSON, `{"x": 0.1, "y": 0.1}, {"x": 0.3`, `{"x": -0.02, "y": 0.1}, {"x": 0.3`, 1)
+	if _, err := UnmarshalResult([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnmarshalResultTrailingData(t *testing.T) {
+	for _, data := range []string{"", validResultJSON + " {}", validResultJSON + " x"} {
+		if _, err := UnmarshalResult([]byte(data)); err == nil {
+			t.Errorf("UnmarshalResult(%.20q...) succeeded", data)
+		}
+	}
+}
+
+func TestJSONL(t *testing.T) {
+	want := roundTripResult()
+	want.finish()
+	var buf bytes.Buffer
+	enc := NewJSONLEncoder(&buf)
+	for range 2 {
+		if err := enc.Encode(want); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf.WriteString(`{"schema_version":1}` + "\n")
+
+	dec := NewJSONLDecoder(&buf)
+	for i := range 2 {
+		got, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("result %d: %v", i, err)
+		}
+		compareBlocks(t, got.Blocks, want.Blocks)
+	}
+	if _, err := dec.Decode(); !errors.Is(err, ErrInvalidResult) {
+		t.Fatalf("incomplete result: err = %v, want ErrInvalidResult", err)
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Fatalf("err = %v, want io.EOF", err)
+	}
+}
+
+func TestResultJSONSchemaBounds(t *testing.T) {
+	// 发布的 schema 与 Validate 使用相同的坐标范围
+	type bounds struct {
+		Ref     string   `json:"$ref"`
+		Minimum *float64 `json:"minimum"`
+		Maximum *float64 `json:"maximum"`
+	}
+	var schema struct {
+		Defs struct {
+			Coordinate  bounds `json:"coordinate"`
+			BoundingBox struct {
+				Properties map[string]bounds `json:"properties"`
+			} `json:"bounding_box"`
+			Point struct {
+				Properties map[string]bounds `json:"properties"`
+			} `json:"point"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal([]byte(ResultJSONSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	check := func(name string, b bounds, min, max float64) {
+		t.Helper()
+		if b.Minimum == nil || b.Maximum == nil || !near(*b.Minimum, min) || !near(*b.Maximum, max) {
+			t.Errorf("%s: schema bounds = %v, %v, want [%v, %v]", name, b.Minimum, b.Maximum, min, max)
+		}
+	}
+	check("coordinate", schema.Defs.Coordinate, -boxMargin, 1+boxMargin)
+	for _, key := range []string{"x", "y"} {
+		for name, props := range map[string]map[string]bounds{"bounding_box": schema.Defs.BoundingBox.Properties, "point": schema.Defs.Point.Properties} {
+			if ref := props[key].Ref; ref != "#/$defs/coordinate" {
+				t.Errorf("%s.%s: $ref = %q, want the coordinate definition", name, key, ref)
+			}
+		}
+	}
+	check("width", schema.Defs.BoundingBox.Properties["width"], 0, 1+2*boxMargin)
+	check("height", schema.Defs.BoundingBox.Properties["height"], 0, 1+2*boxMargin)
+
+	// 边界值与 Validate 一致
+	for _, tt := range []struct {
+		box   BoundingBox
+		valid bool
+	}{
+		{BoundingBox{-boxMargin, -boxMargin, 1 + 2*boxMargin, 1 + 2*boxMargin}, true},
+		{BoundingBox{-0.06, 0, 0.5, 0.5}, false},
+		{BoundingBox{0, 1.06, 0, 0}, false},
+		{BoundingBox{0, 0, 1.11, 0.5}, false},
+	} {
+		r := &Result{SchemaVersion: SchemaVersion1, Blocks: []TextBlock{{BoundingBox: tt.box, Quad: tt.box.Quad()}}}
+		if err := r.Validate(); (err == nil) != tt.valid {
+			t.Errorf("Validate(%+v) = %v, want valid %v", tt.box, err, tt.valid)
+		}
+	}
+}
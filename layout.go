@@ -0,0 +1,214 @@
+package sysocr
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// 版面分析的阈值，均以行高为单位。
+const (
+	layoutRowGap       = 1.0 // 同一行内两段文本的最大水平间距
+	layoutRegionGap    = 1.5 // 同一区域内上下两行的最大垂直间距
+	layoutParagraphGap = 0.8 // 超过该垂直间距时开始新段落
+	layoutHeightRatio  = 1.3 // 相邻两行行高之比超过该值时开始新段落（如标题与正文）
+	layoutIndent       = 1.0 // 首行缩进超过该值时开始新段落
+	layoutShortLine    = 4.0 // 上一行右侧留白超过该值时视为段落结束
+)
+
+// layoutLine 是像素空间中的一行，用于版面分析。
+type layoutLine struct {
+	index int
+	pixelRect
+}
+
+// AnalyzeLayout 根据各行的位置将 blocks 组织为区域、段落和行的层级结构。
+//
+// 分析只依赖边界框，不依赖具体引擎：紧接在区域最下面一行之下且水平方向重叠、或与区域中某行同一行且距离较近的行
+// 归入该区域；区域内根据行距、行高变化、首行缩进和短行结尾划分段落。
+// 区域按其第一行在 blocks 中出现的顺序排列，区域内的行保持 blocks 中的顺序，
+// 因此先用 SortReadingOrder 排序即可得到分栏的阅读顺序。
+// imageWidth、imageHeight 用于将归一化坐标还原为像素比例，未知时传 0。
+func AnalyzeLayout(blocks []TextBlock, imageWidth, imageHeight int) *Page {
+	page := &Page{Width: imageWidth, Height: imageHeight}
+	if len(blocks) == 0 {
+		return page
+	}
+
+	sx, sy := pixelScale(imageWidth, imageHeight)
+	lines := make([]layoutLine, len(blocks))
+	for i, b := range blocks {
+		lines[i] = layoutLine{index: i, pixelRect: b.BoundingBox.pixelRect(sx, sy)}
+	}
+
+	// 按 blocks 的顺序把每一行并入第一个与之邻接的区域，没有则开始新区域
+	var groups [][]layoutLine
+	for _, l := range lines {
+		joined := false
+		for i, g := range groups {
+			if joinsRegion(g, l) {
+				groups[i] = append(g, l)
+				joined = true
+				break
+			}
+		}
+		if !joined {
+			groups = append(groups, []layoutLine{l})
+		}
+	}
+
+	for _, g := range groups {
+		page.Regions = append(page.Regions, buildRegion(blocks, g))
+	}
+	return page
+}
+
+// joinsRegion 判断 l 是否属于已有的区域 region。
+//
+// l 与区域中某一行处于同一行且距离较近，或紧接在区域最下面一行之下（最上面一行之上）并与之水平重叠时属于该区域。
+// 只与最外侧的行比较，使横跨两栏的标题不会把下方的两栏连成一个区域。
+func joinsRegion(region []layoutLine, l layoutLine) bool {
+	top, bottom := region[0], region[0]
+	for _, m := range region {
+		if sameRowAdjacent(m, l) {
+			return true
+		}
+		if m.centerY() < top.centerY() {
+			top = m
+		}
+		if m.centerY() > bottom.centerY() {
+			bottom = m
+		}
+	}
+	for _, m := range region {
+		// 最下面（最上面）一行可能被引擎拆成了几段
+		if sameRow(m, bottom) && l.centerY() > m.centerY() && stackedAdjacent(m, l) {
+			return true
+		}
+		if sameRow(m, top) && l.centerY() < m.centerY() && stackedAdjacent(l, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameRow 判断两行是否垂直方向重叠超过较矮一行的一半，即属于同一行。
+func sameRow(a, b layoutLine) bool {
+	overlapY := min(a.y1, b.y1) - max(a.y0, b.y0)
+	return overlapY > min(a.height(), b.height())/2
+}
+
+// sameRowAdjacent 判断同一行内的两段文本是否足够近，如被引擎拆开的一行。
+func sameRowAdjacent(a, b layoutLine) bool {
+	h := max(a.height(), b.height())
+	if h <= 0 || !sameRow(a, b) {
+		return false
+	}
+	overlapX := min(a.x1, b.x1) - max(a.x0, b.x0)
+	return -overlapX <= layoutRowGap*h
+}
+
+// stackedAdjacent 判断 lower 是否紧接在 upper 之下且水平方向重叠。
+func stackedAdjacent(upper, lower layoutLine) bool {
+	h := max(upper.height(), lower.height())
+	if h <= 0 || sameRow(upper, lower) {
+		return false
+	}
+	overlapX := min(upper.x1, lower.x1) - max(upper.x0, lower.x0)
+	return overlapX > 0 && lower.y0-upper.y1 <= layoutRegionGap*h
+}
+
+// buildRegion 将同一区域的行划分段落，行保持 blocks 中的顺序。
+func buildRegion(blocks []TextBlock, lines []layoutLine) Region {
+	var region Region
+	var para Paragraph
+	for i, l := range lines {
+		if left, right := textBounds(lines, l); i > 0 && paragraphBreak(lines[i-1], l, left, right) {
+			region.Paragraphs = append(region.Paragraphs, para)
+			region.BoundingBox = region.BoundingBox.Union(para.BoundingBox)
+			para = Paragraph{}
+		}
+		b := blocks[l.index]
+		para.Lines = append(para.Lines, b)
+		para.BoundingBox = para.BoundingBox.Union(b.BoundingBox)
+	}
+	region.Paragraphs = append(region.Paragraphs, para)
+	region.BoundingBox = region.BoundingBox.Union(para.BoundingBox)
+	return region
+}
+
+// textBounds 返回区域中与 l 行高相近的行的左右边界，
+// 排除标题等行高不同的行，使横跨两栏的标题不影响下方各栏的缩进和短行判断。
+func textBounds(lines []layoutLine, l layoutLine) (left, right float64) {
+	left, right = l.x0, l.x1
+	for _, m := range lines {
+		hl, hm := l.height(), m.height()
+		if hl > 0 && hm > 0 && max(hl, hm)/min(hl, hm) <= layoutHeightRatio {
+			left, right = min(left, m.x0), max(right, m.x1)
+		}
+	}
+	return left, right
+}
+
+// paragraphBreak 判断 cur 是否开始一个新段落，left、right 为区域的左右边界。
+func paragraphBreak(prev, cur layoutLine, left, right float64) bool {
+	hp, hc := prev.height(), cur.height()
+	if hp <= 0 || hc <= 0 {
+		return false
+	}
+	// 同一行内的文本不拆分
+	if cur.y0 < prev.y1 && cur.centerY()-prev.centerY() < min(hp, hc)/2 {
+		return false
+	}
+	if cur.y0-prev.y1 > layoutParagraphGap*(hp+hc)/2 {
+		return true
+	}
+	if max(hp, hc)/min(hp, hc) > layoutHeightRatio {
+		return true
+	}
+	if cur.x0-left > layoutIndent*hc && prev.x0-left < layoutIndent*hp/2 {
+		return true
+	}
+	return right-prev.x1 > layoutShortLine*hp
+}
+
+// Lines 按页面顺序返回所有行。
+func (p *Page) Lines() []TextBlock {
+	var lines []TextBlock
+	for _, r := range p.Regions {
+		for _, para := range r.Paragraphs {
+			lines = append(lines, para.Lines...)
+		}
+	}
+	return lines
+}
+
+// Text 返回页面文本，段落之间以空行分隔。
+func (p *Page) Text() string {
+	var sb strings.Builder
+	for _, r := range p.Regions {
+		for _, para := range r.Paragraphs {
+			if sb.Len() > 0 {
+				sb.WriteString("\n\n")
+			}
+			sb.WriteString(para.Text())
+		}
+	}
+	return sb.String()
+}
+
+// Text 返回段落文本。折行处以空格连接，两侧为全角字符（如中日韩文字）时直接连接。
+func (p Paragraph) Text() string {
+	var sb strings.Builder
+	for i, l := range p.Lines {
+		text := strings.TrimSpace(l.Text)
+		if i > 0 && sb.Len() > 0 && text != "" {
+			last, _ := utf8.DecodeLastRuneInString(sb.String())
+			first, _ := utf8.DecodeRuneInString(text)
+			if !isWide(last) || !isWide(first) {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(text)
+	}
+	return sb.String()
+}
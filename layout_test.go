@@ -0,0 +1,103 @@
+package sysocr
+
+import "testing"
+
+// line 返回一个像素坐标为 (x, y, w, h)、页面为 1000×1000 的文本块。
+func line(text string, x, y, w, h float64) TextBlock {
+	return TextBlock{Text: text, BoundingBox: BoundingBox{X: x / 1000, Y: y / 1000, Width: w / 1000, Height: h / 1000}}
+}
+
+// twoColumnPage 返回横跨两栏的标题和其下的两栏正文，按引擎的逐行交错顺序排列。
+func twoColumnPage() []TextBlock {
+	return []TextBlock{
+		line("Title spanning", 100, 50, 800, 40),
+		line("L1", 100, 120, 350, 20), line("R1", 550, 120, 350, 20),
+		line("L2", 100, 150, 350, 20), line("R2", 550, 150, 350, 20),
+		line("L3", 100, 180, 350, 20), line("R3", 550, 180, 350, 20),
+	}
+}
+
+func regionTexts(p *Page) [][]string {
+	var out [][]string
+	for _, r := range p.Regions {
+		var texts []string
+		for _, para := range r.Paragraphs {
+			texts = append(texts, para.Text())
+		}
+		out = append(out, texts)
+	}
+	return out
+}
+
+func TestAnalyzeLayoutColumnsUnderTitle(t *testing.T) {
+	blocks := SortReadingOrder(twoColumnPage(), ReadingOrderLeftToRight, 1000, 1000)
+	page := AnalyzeLayout(blocks, 1000, 1000)
+	if want := "Title spanning\n\nL1 L2 L3\n\nR1 R2 R3"; page.Text() != want {
+		t.Fatalf("Page.Text() = %q, want %q (regions %q)", page.Text(), want, regionTexts(page))
+	}
+	if len(page.Regions) != 2 {
+		t.Fatalf("got %d regions %q, want the title with the left column and the right column", len(page.Regions), regionTexts(page))
+	}
+}
+
+func TestAnalyzeLayoutEngineOrder(t *testing.T) {
+	// 不排序时两栏仍然是独立的区域，区域按第一行出现的顺序排列
+	page := AnalyzeLayout(twoColumnPage(), 1000, 1000)
+	if want := "Title spanning\n\nL1 L2 L3\n\nR1 R2 R3"; page.Text() != want {
+		t.Fatalf("Page.Text() = %q, want %q", page.Text(), want)
+	}
+}
+
+func TestAnalyzeLayoutKeepsOrder(t *testing.T) {
+	// 区域内的行保持 blocks 中的顺序，不按位置重新排序
+	blocks := []TextBlock{
+		line("second", 100, 130, 300, 20),
+		line("first", 100, 100, 300, 20),
+	}
+	lines := AnalyzeLayout(blocks, 1000, 1000).Lines()
+	if len(lines) != 2 || lines[0].Text != "second" || lines[1].Text != "first" {
+		t.Fatalf("Lines() = %+v, want the blocks' order", lines)
+	}
+}
+
+func TestAnalyzeLayoutParagraphs(t *testing.T) {
+	blocks := []TextBlock{
+		line("Heading", 100, 50, 300, 40),
+		line("First paragraph line one that is long", 100, 110, 700, 20),
+		line("ends here.", 100, 140, 200, 20),
+		line("Second paragraph starts", 140, 170, 660, 20),
+		line("and continues.", 100, 200, 700, 20),
+		// 同一行被拆成两段
+		line("Split", 100, 230, 100, 20), line("line", 220, 230, 100, 20),
+	}
+	page := AnalyzeLayout(blocks, 1000, 1000)
+	want := []string{
+		"Heading",
+		"First paragraph line one that is long ends here.",
+		"Second paragraph starts and continues. Split line",
+	}
+	got := regionTexts(page)
+	if len(got) != 1 || len(got[0]) != len(want) {
+		t.Fatalf("regions = %q, want one region with paragraphs %q", got, want)
+	}
+	for i := range want {
+		if got[0][i] != want[i] {
+			t.Errorf("paragraph %d = %q, want %q", i, got[0][i], want[i])
+		}
+	}
+}
+
+func TestAnalyzeLayoutSeparateRegions(t *testing.T) {
+	blocks := []TextBlock{
+		line("Main text", 100, 100, 500, 20),
+		line("more text", 100, 130, 500, 20),
+		line("Footer", 100, 900, 200, 20),
+	}
+	page := AnalyzeLayout(blocks, 1000, 1000)
+	if len(page.Regions) != 2 || page.Regions[1].Paragraphs[0].Text() != "Footer" {
+		t.Fatalf("regions = %q", regionTexts(page))
+	}
+	if page.Width != 1000 || len(AnalyzeLayout(nil, 0, 0).Regions) != 0 {
+		t.Fatal("unexpected page size or regions for empty input")
+	}
+}
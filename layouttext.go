@@ -0,0 +1,153 @@
+package sysocr
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// LayoutOptions 配置 RenderLayout 的字符网格。
+type LayoutOptions struct {
+	// Columns 是图片宽度对应的字符列数，0 表示根据文本的平均字符宽度自动估算。
+	Columns int
+}
+
+// layoutUnit 是放到字符网格上的一段文本（单词或整行）。
+type layoutUnit struct {
+	text string
+	x    float64
+}
+
+// layoutRow 是网格中的一行，由垂直方向重叠的文本块组成。
+type layoutRow struct {
+	y0, y1 float64
+	units  []layoutUnit
+}
+
+// LayoutText 以等宽文本近似还原页面版面，参见 RenderLayout。
+func (r *Result) LayoutText() string {
+	return RenderLayout(r.Blocks, LayoutOptions{})
+}
+
+// RenderLayout 根据各文本块的边界框把文本放到字符网格上，输出保留水平位置和空行的等宽文本，
+// 效果类似 pdftotext -layout，适用于终端截图和定宽报表。
+//
+// 有单词信息时按单词定位，以保留行内的对齐空白；全角字符占两列。
+// 同一行中位置重叠的文本之间至少保留一个空格，不会相互覆盖。
+func RenderLayout(blocks []TextBlock, opts LayoutOptions) string {
+	if len(blocks) == 0 {
+		return ""
+	}
+
+	charWidth := 0.0
+	if opts.Columns > 0 {
+		charWidth = 1 / float64(opts.Columns)
+	} else {
+		charWidth = estimateCharWidth(blocks)
+	}
+	if charWidth <= 0 {
+		return joinText(blocks)
+	}
+
+	rows := layoutRows(blocks)
+	pitch := linePitch(rows)
+
+	var sb strings.Builder
+	prevLine := 0
+	for i, row := range rows {
+		// 按与第一行的距离换算行号，行间的空白还原为空行
+		line := prevLine + 1
+		if i == 0 {
+			line = 0
+		} else if pitch > 0 {
+			line = max(line, int(math.Round((center(row.y0, row.y1)-center(rows[0].y0, rows[0].y1))/pitch)))
+		}
+		for n := prevLine; n < line; n++ {
+			sb.WriteByte('\n')
+		}
+		prevLine = line
+
+		sort.SliceStable(row.units, func(a, b int) bool { return row.units[a].x < row.units[b].x })
+		var lb strings.Builder
+		cursor := 0
+		for _, u := range row.units {
+			col := int(math.Round(u.x / charWidth))
+			switch {
+			case col > cursor:
+				lb.WriteString(strings.Repeat(" ", col-cursor))
+				cursor = col
+			case cursor > 0:
+				lb.WriteByte(' ')
+				cursor++
+			}
+			lb.WriteString(u.text)
+			cursor += textWidth(u.text)
+		}
+		sb.WriteString(strings.TrimRight(lb.String(), " "))
+	}
+	return sb.String()
+}
+
+// estimateCharWidth 返回半角字符的归一化宽度中位数。
+func estimateCharWidth(blocks []TextBlock) float64 {
+	var widths []float64
+	for _, b := range blocks {
+		if n := textWidth(b.Text); n > 0 && b.BoundingBox.Width > 0 {
+			widths = append(widths, b.BoundingBox.Width/float64(n))
+		}
+	}
+	if len(widths) == 0 {
+		return 0
+	}
+	sort.Float64s(widths)
+	return widths[len(widths)/2]
+}
+
+// layoutRows 把垂直方向重叠的文本块合并为网格行，按从上到下排列。
+func layoutRows(blocks []TextBlock) []layoutRow {
+	sorted := make([]TextBlock, len(blocks))
+	copy(sorted, blocks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].BoundingBox, sorted[j].BoundingBox
+		return a.Y+a.Height/2 < b.Y+b.Height/2
+	})
+
+	var rows []layoutRow
+	for _, b := range sorted {
+		box := b.BoundingBox
+		y0, y1 := box.Y, box.Y+box.Height
+		if n := len(rows); n == 0 || !sameBand(rows[n-1].y0, rows[n-1].y1, y0, y1) {
+			rows = append(rows, layoutRow{y0: y0, y1: y1})
+		}
+		row := &rows[len(rows)-1]
+		row.y0, row.y1 = min(row.y0, y0), max(row.y1, y1)
+		if len(b.Words) > 0 {
+			for _, w := range b.Words {
+				row.units = append(row.units, layoutUnit{text: w.Text, x: w.BoundingBox.X})
+			}
+		} else {
+			row.units = append(row.units, layoutUnit{text: strings.TrimSpace(b.Text), x: box.X})
+		}
+	}
+	return rows
+}
+
+// linePitch 估算行距：相邻网格行中心距离的最小值，
+// 但不小于行高的中位数，以免挤在一起的两行把其余行距放大为空行。
+func linePitch(rows []layoutRow) float64 {
+	if len(rows) < 2 {
+		return 0
+	}
+	heights := make([]float64, len(rows))
+	for i, r := range rows {
+		heights[i] = r.y1 - r.y0
+	}
+	sort.Float64s(heights)
+	pitch := math.Inf(1)
+	for i := 1; i < len(rows); i++ {
+		pitch = min(pitch, center(rows[i].y0, rows[i].y1)-center(rows[i-1].y0, rows[i-1].y1))
+	}
+	return max(pitch, heights[len(heights)/2])
+}
+
+func center(a, b float64) float64 { return (a + b) / 2 }
@@ -0,0 +1,109 @@
+package sysocr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderLayout(t *testing.T) {
+	sp := func(n int) string { return strings.Repeat(" ", n) }
+	// 坐标为 1000×1000 页面上的像素，Columns 为 100 时每列 10 像素
+	for _, tt := range []struct {
+		name    string
+		blocks  []TextBlock
+		columns int
+		want    string
+	}{
+		{
+			name: "columns",
+			blocks: []TextBlock{
+				line("Name", 0, 0, 40, 20), line("Qty", 300, 0, 30, 20),
+				line("Apple", 0, 30, 50, 20), line("3", 300, 30, 10, 20),
+			},
+			columns: 100,
+			want:    "Name" + sp(26) + "Qty\nApple" + sp(25) + "3",
+		},
+		{
+			// 行距为 30，第三行与第二行相距 60，中间还原一个空行
+			name:    "blank lines",
+			blocks:  []TextBlock{line("A", 0, 0, 10, 20), line("B", 0, 30, 10, 20), line("C", 0, 90, 10, 20)},
+			columns: 100,
+			want:    "A\nB\n\nC",
+		},
+		{
+			// A 与 B 的中心只相距 14，行距取行高的中位数 20，其余行不会被放大为空行
+			name: "crowded rows",
+			blocks: []TextBlock{
+				line("A", 0, 0, 10, 20), line("B", 0, 22, 10, 4),
+				line("C", 0, 40, 10, 20), line("D", 0, 60, 10, 20),
+			},
+			columns: 100,
+			want:    "A\nB\nC\nD",
+		},
+		{
+			// 全角字符占两列，后面的文本仍然对齐
+			name: "wide characters",
+			blocks: []TextBlock{
+				line("中文", 0, 0, 40, 20), line("x", 100, 0, 10, 20),
+				line("abcd", 0, 30, 40, 20), line("y", 100, 30, 10, 20),
+			},
+			columns: 100,
+			want:    "中文" + sp(6) + "x\nabcd" + sp(6) + "y",
+		},
+		{
+			// 位置重叠的文本之间保留一个空格
+			name:    "overlap",
+			blocks:  []TextBlock{line("Hello", 0, 0, 50, 20), line("world", 20, 0, 50, 20)},
+			columns: 100,
+			want:    "Hello world",
+		},
+		{
+			// 有单词信息时按单词定位
+			name:    "words",
+			blocks:  []TextBlock{wordsLine(0, 20, "a", 0.0, 10.0, "b", 500.0, 10.0)},
+			columns: 100,
+			want:    "a" + sp(49) + "b",
+		},
+		{
+			// 输入顺序不影响输出，行按从上到下排列
+			name:    "unordered",
+			blocks:  []TextBlock{line("second", 0, 30, 60, 20), line("first", 100, 0, 50, 20)},
+			columns: 100,
+			want:    sp(10) + "first\nsecond",
+		},
+		{
+			// Columns 为 0 时由平均字符宽度（10 像素）估算
+			name:   "estimated columns",
+			blocks: []TextBlock{line("abcd", 0, 0, 40, 20), line("x", 200, 0, 10, 20)},
+			want:   "abcd" + sp(16) + "x",
+		},
+		{
+			// Columns 决定网格宽度，与字符宽度无关
+			name:    "fixed columns",
+			blocks:  []TextBlock{line("abcd", 0, 0, 40, 20), line("x", 200, 0, 10, 20)},
+			columns: 20,
+			want:    "abcd x",
+		},
+		{
+			// 无法估算字符宽度时退化为按行拼接
+			name:   "no width",
+			blocks: []TextBlock{line("a", 0, 0, 0, 20), line("b", 0, 30, 0, 20)},
+			want:   "a\nb",
+		},
+		{name: "empty"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderLayout(tt.blocks, LayoutOptions{Columns: tt.columns})
+			if got != tt.want {
+				t.Errorf("RenderLayout =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayoutText(t *testing.T) {
+	r := &Result{Blocks: []TextBlock{line("abcd", 0, 0, 40, 20), line("x", 200, 0, 10, 20)}}
+	if got, want := r.LayoutText(), "abcd"+strings.Repeat(" ", 16)+"x"; got != want {
+		t.Errorf("LayoutText = %q, want %q", got, want)
+	}
+}
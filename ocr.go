@@ -1,4 +1,183 @@
 // Package sysocr 提供跨平台的 OCR 功能，调用操作系统原生 OCR 接口。
 //
-// 支持平台: macOS (Vision Framework), Windows 10/11 (Windows.Media.Ocr)
+// 支持平台: macOS (Vision Framework), Windows 10/11 (Windows.Media.Ocr),
+// Linux (本地 tesseract 可执行文件)
+//
+// 各平台后端以 Engine 的形式注册，Recognize 默认使用当前平台的引擎，
+// 也可以通过 Register 注册自定义引擎并用 Options.Engine 指定。
 package sysocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnsupportedPlatform 表示当前平台没有可用的 OCR 引擎，且未注册自定义引擎。
+var ErrUnsupportedPlatform = errors.New("sysocr: no OCR engine available on this platform")
+
+func init() {
+	if e := platformEngine(); e != nil {
+		Register(e)
+	}
+}
+
+// Available 报告当前是否有可用的默认引擎。
+// 可用于在不支持 OCR 的平台上提前降级，而不必等到 Recognize 返回 ErrUnsupportedPlatform。
+func Available() bool {
+	e := DefaultEngine()
+	if e == nil {
+		return false
+	}
+	if c, ok := e.(interface{ Available() bool }); ok {
+		return c.Available()
+	}
+	return true
+}
+
+// Recognize 对提供的图片进行 OCR 识别。
+func Recognize(opts Options) (*Result, error) {
+	return RecognizeContext(context.Background(), opts)
+}
+
+// RecognizeContext 与 Recognize 相同，但在获取输入、解码和识别的过程中响应 ctx 的取消和截止时间。
+// ctx 结束后返回的错误包装了 ctx.Err()，可以用 errors.Is 判断。
+func RecognizeContext(ctx context.Context, opts Options) (*Result, error) {
+	engine, err := selectEngine(opts.Engine)
+	if err != nil {
+		return nil, err
+	}
+
+	// 将输入转换为字节数据
+	data, err := resolveInput(ctx, opts.Input, opts.Fetch, opts.MaxImagePixels)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	// 在调用后端之前检查格式和尺寸，避免后端返回含义模糊的错误
+	caps := engine.Capabilities()
+	info, err := validateImage(data, opts.MaxImagePixels, caps)
+	if err != nil {
+		return nil, err
+	}
+
+	// 各后端对 EXIF 方向的处理不一致，统一在这里校正为用户看到的方向
+	var orientation Orientation
+	if !opts.IgnoreOrientation {
+		data, info, orientation, err = normalizeOrientation(data, info)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := engine.Recognize(ctx, data, EngineOptions{
+		Languages:     opts.Languages,
+		MaxCandidates: opts.MaxCandidates,
+	})
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	if result == nil {
+		return nil, errors.New("sysocr: engine " + engine.Name() + " returned no result")
+	}
+	if info.Width == 0 {
+		// 引擎自行解码了无法识别格式的数据（Capabilities.RawInput），尺寸以引擎为准
+		info.Width, info.Height = result.ImageWidth, result.ImageHeight
+	}
+
+	if !caps.Confidence {
+		// 不提供置信度的引擎可能没有设置 Confidence，零值不代表置信度为 0
+		fillUnknownConfidence(result.Blocks)
+	} else if opts.MinConfidence > 0 {
+		result.Blocks = filterConfidence(result.Blocks, opts.MinConfidence)
+	}
+	// 引擎可能返回比请求更多的候选结果，未请求（MaxCandidates 为 0）时全部清除
+	for i := range result.Blocks {
+		b := &result.Blocks[i]
+		if opts.MaxCandidates <= 0 {
+			b.Candidates = nil
+		} else if len(b.Candidates) > opts.MaxCandidates {
+			b.Candidates = b.Candidates[:opts.MaxCandidates]
+		}
+	}
+	if opts.ReadingOrder != ReadingOrderEngine {
+		result.Blocks = SortReadingOrder(result.Blocks, opts.ReadingOrder, info.Width, info.Height)
+	}
+	result.ImageWidth, result.ImageHeight = info.Width, info.Height
+	result.Orientation = orientation
+	result.finish()
+	return result, nil
+}
+
+// finish 补齐识别或从其他格式解码得到的结果：SchemaVersion、Quad、TextAngle、Text 和 Page。
+// 调用前需要设置好 ImageWidth、ImageHeight 并确定文本块的顺序。
+func (r *Result) finish() {
+	r.SchemaVersion = SchemaVersion1
+	for i := range r.Blocks {
+		if r.Blocks[i].Quad.IsZero() {
+			r.Blocks[i].Quad = r.Blocks[i].BoundingBox.Quad()
+		}
+	}
+	if r.TextAngle == 0 {
+		r.TextAngle = estimateTextAngle(r.Blocks, r.ImageWidth, r.ImageHeight)
+	}
+	r.Text = joinText(r.Blocks)
+	r.Page = AnalyzeLayout(r.Blocks, r.ImageWidth, r.ImageHeight)
+}
+
+// contextError 在 ctx 已结束时将 err 替换为包装了 ctx.Err() 的错误，
+// 使得后端因取消而产生的各种错误都能用 errors.Is(err, context.Canceled) 判断。
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("sysocr: recognition aborted: %w", ctxErr)
+	}
+	return err
+}
+
+// filterConfidence 丢弃置信度已知且低于 min 的文本块。
+func filterConfidence(blocks []TextBlock, min float64) []TextBlock {
+	kept := blocks[:0]
+	for _, b := range blocks {
+		if b.Confidence >= 0 && b.Confidence < min {
+			continue
+		}
+		kept = append(kept, b)
+	}
+	return kept
+}
+
+// fillUnknownConfidence 将文本块、单词和候选结果中为零值的置信度设为 UnknownConfidence。
+func fillUnknownConfidence(blocks []TextBlock) {
+	for i := range blocks {
+		b := &blocks[i]
+		if b.Confidence == 0 {
+			b.Confidence = UnknownConfidence
+		}
+		for j := range b.Words {
+			if b.Words[j].Confidence == 0 {
+				b.Words[j].Confidence = UnknownConfidence
+			}
+		}
+		for j := range b.Candidates {
+			if b.Candidates[j].Confidence == 0 {
+				b.Candidates[j].Confidence = UnknownConfidence
+			}
+		}
+	}
+}
+
+// joinText 按顺序用换行拼接所有文本块。
+func joinText(blocks []TextBlock) string {
+	var textBuilder strings.Builder
+	for i, b := range blocks {
+		if i > 0 {
+			textBuilder.WriteString("\n")
+		}
+		textBuilder.WriteString(b.Text)
+	}
+	return textBuilder.String()
+}
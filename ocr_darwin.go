@@ -3,31 +3,54 @@
 package sysocr
 
 import (
-	"strings"
+	"context"
 
 	"github.com/zn-chen/sysocr/internal/darwin"
 )
 
-// Recognize 对提供的图片进行 OCR 识别。
-func Recognize(opts Options) (*Result, error) {
-	// 将输入转换为字节数据
-	data, err := resolveInput(opts.Input)
-	if err != nil {
-		return nil, err
+// platformEngine 返回 macOS 的默认引擎。
+func platformEngine() Engine {
+	return visionEngine{}
+}
+
+// visionEngine 使用 macOS Vision Framework 进行识别。
+type visionEngine struct{}
+
+func (visionEngine) Name() string { return "vision" }
+
+func (visionEngine) Capabilities() Capabilities {
+	return Capabilities{LanguageHints: true, Words: true, Confidence: true, Candidates: true}
+}
+
+func (visionEngine) Recognize(ctx context.Context, data []byte, opts EngineOptions) (*Result, error) {
+	// Vision 的识别请求是同步调用，在独立的 goroutine 中执行以便响应 ctx 取消；
+	// 取消后识别仍会在后台完成，结果被丢弃。
+	type outcome struct {
+		result *darwin.Result
+		err    error
 	}
+	done := make(chan outcome, 1)
+	go func() {
+		// 调用平台特定实现
+		r, err := darwin.Recognize(data, opts.Languages, opts.MaxCandidates)
+		done <- outcome{r, err}
+	}()
 
-	// 调用平台特定实现
-	darwinResult, err := darwin.Recognize(data, opts.Languages)
-	if err != nil {
-		return nil, err
+	var darwinResult *darwin.Result
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case o := <-done:
+		if o.err != nil {
+			return nil, o.err
+		}
+		darwinResult = o.result
 	}
 
 	// 转换为公共类型
 	result := &Result{
 		Blocks: make([]TextBlock, len(darwinResult.Blocks)),
 	}
-
-	var textBuilder strings.Builder
 	for i, b := range darwinResult.Blocks {
 		result.Blocks[i] = TextBlock{
 			Text: b.Text,
@@ -37,13 +60,39 @@ func Recognize(opts Options) (*Result, error) {
 				Width:  b.Width,
 				Height: b.Height,
 			},
+			Confidence: b.Confidence,
+			Quad: Quad{
+				{b.Quad[0], b.Quad[1]},
+				{b.Quad[2], b.Quad[3]},
+				{b.Quad[4], b.Quad[5]},
+				{b.Quad[6], b.Quad[7]},
+			},
+		}
+		if len(b.Words) > 0 {
+			words := make([]Word, len(b.Words))
+			for j, w := range b.Words {
+				words[j] = Word{
+					Text: w.Text,
+					BoundingBox: BoundingBox{
+						X:      w.X,
+						Y:      w.Y,
+						Width:  w.Width,
+						Height: w.Height,
+					},
+					// Vision 只提供整行候选文本的置信度
+					Confidence: UnknownConfidence,
+				}
+			}
+			result.Blocks[i].Words = words
 		}
-		if i > 0 {
-			textBuilder.WriteString("\n")
+		if len(b.Candidates) > 0 {
+			candidates := make([]Candidate, len(b.Candidates))
+			for j, c := range b.Candidates {
+				candidates[j] = Candidate{Text: c.Text, Confidence: c.Confidence}
+			}
+			result.Blocks[i].Candidates = candidates
 		}
-		textBuilder.WriteString(b.Text)
 	}
-	result.Text = textBuilder.String()
 
 	return result, nil
 }
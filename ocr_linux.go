@@ -0,0 +1,286 @@
+//go:build linux
+
+package sysocr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// platformEngine 返回 Linux 的默认引擎。
+func platformEngine() Engine {
+	return &TesseractEngine{}
+}
+
+// TesseractEngine 通过调用本地 tesseract 可执行文件进行识别，解析其 TSV 输出。
+//
+// 默认注册的实例名称为 "tesseract"，在 PATH 中查找可执行文件。
+// 需要使用其他路径时可以注册新的实例：
+//
+//	sysocr.Register(&sysocr.TesseractEngine{EngineName: "tess5", Path: "/opt/tesseract/bin/tesseract"})
+type TesseractEngine struct {
+	EngineName string   // 注册名称，默认为 "tesseract"
+	Path       string   // 可执行文件路径，为空时在 PATH 中查找 "tesseract"
+	Args       []string // 额外的命令行参数，如 "--psm", "6"
+}
+
+// Name 实现 Engine 接口。
+func (t *TesseractEngine) Name() string {
+	if t.EngineName == "" {
+		return "tesseract"
+	}
+	return t.EngineName
+}
+
+// Available 报告 tesseract 可执行文件是否存在。
+func (t *TesseractEngine) Available() bool {
+	_, err := exec.LookPath(t.path())
+	return err == nil
+}
+
+// Capabilities 实现 Engine 接口。
+func (t *TesseractEngine) Capabilities() Capabilities {
+	return Capabilities{
+		LanguageHints: true,
+		Words:         true,
+		Confidence:    true,
+		// Leptonica 不能解码 HEIF
+		Formats: []ImageFormat{FormatPNG, FormatJPEG, FormatGIF, FormatBMP, FormatTIFF, FormatWebP},
+	}
+}
+
+// Recognize 实现 Engine 接口。图片数据通过标准输入传给 tesseract。
+func (t *TesseractEngine) Recognize(ctx context.Context, data []byte, opts EngineOptions) (*Result, error) {
+	args := []string{"stdin", "stdout"}
+	if len(opts.Languages) > 0 {
+		args = append(args, "-l", tesseractLanguages(opts.Languages))
+	}
+	args = append(args, t.Args...)
+	args = append(args, "tsv")
+
+	cmd := exec.CommandContext(ctx, t.path(), args...)
+	cmd.WaitDelay = time.Second // 取消后不再等待子进程遗留的输出管道
+	cmd.Stdin = bytes.NewReader(data)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("%w: tesseract executable not found", ErrUnsupportedPlatform)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, errors.New("sysocr: tesseract failed: " + msg)
+	}
+
+	return parseTesseractTSV(stdout.Bytes())
+}
+
+// path 返回要执行的 tesseract 路径。
+func (t *TesseractEngine) path() string {
+	if t.Path == "" {
+		return "tesseract"
+	}
+	return t.Path
+}
+
+// tesseractLangCodes 将常见的 BCP 47 语言标签映射为 tesseract 的语言包名称。
+var tesseractLangCodes = map[string]string{
+	"en":      "eng",
+	"zh-Hans": "chi_sim",
+	"zh-CN":   "chi_sim",
+	"zh-Hant": "chi_tra",
+	"zh-TW":   "chi_tra",
+	"ja":      "jpn",
+	"ko":      "kor",
+	"de":      "deu",
+	"fr":      "fra",
+	"es":      "spa",
+	"it":      "ita",
+	"pt":      "por",
+	"ru":      "rus",
+}
+
+// tesseractLanguages 将语言提示转换为 tesseract 的 -l 参数，无法映射的值原样传递。
+func tesseractLanguages(languages []string) string {
+	codes := make([]string, 0, len(languages))
+	for _, lang := range languages {
+		if code, ok := tesseractLangCodes[lang]; ok {
+			lang = code
+		} else if i := strings.IndexByte(lang, '-'); i > 0 {
+			if code, ok := tesseractLangCodes[lang[:i]]; ok {
+				lang = code
+			}
+		}
+		codes = append(codes, lang)
+	}
+	return strings.Join(codes, "+")
+}
+
+// TSV 中每一行的层级
+const (
+	tsvLevelPage = 1
+	tsvLevelLine = 4
+	tsvLevelWord = 5
+)
+
+// tsvRow 表示 tesseract TSV 输出中的一行。
+type tsvRow struct {
+	level                    int
+	page, block, par, line   int
+	left, top, width, height float64
+	conf                     float64
+	text                     string
+}
+
+// tsvLineKey 唯一标识输出中的一行
+type tsvLineKey struct {
+	page, block, par, line int
+}
+
+// parseTesseractTSV 解析 tesseract 的 TSV 输出，生成行级别的文本块。
+// 坐标按页面尺寸归一化到 0-1，原点在左上角。
+// 为每行同时填充 TextBlock.Words。
+// 多页 TIFF 会产生多个页面，与 DetectImage 一致只保留第一页。
+func parseTesseractTSV(out []byte) (*Result, error) {
+	page := -1 // 第一页的 page_num，读到页面行之前为 -1
+	var pageWidth, pageHeight float64
+	var order []tsvLineKey
+	lines := make(map[tsvLineKey]*tsvRow)
+	words := make(map[tsvLineKey][]*tsvRow)
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		row, ok := parseTSVRow(scanner.Text())
+		if !ok {
+			continue
+		}
+		if row.level == tsvLevelPage && page < 0 {
+			page, pageWidth, pageHeight = row.page, row.width, row.height
+		}
+		if row.page != page {
+			continue
+		}
+		key := tsvLineKey{row.page, row.block, row.par, row.line}
+		switch row.level {
+		case tsvLevelLine:
+			if _, seen := lines[key]; !seen {
+				order = append(order, key)
+			}
+			lines[key] = row
+		case tsvLevelWord:
+			if row.text = strings.TrimSpace(row.text); row.text != "" {
+				words[key] = append(words[key], row)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.New("sysocr: failed to read tesseract output: " + err.Error())
+	}
+	if pageWidth <= 0 || pageHeight <= 0 {
+		return nil, errors.New("sysocr: tesseract output has no page dimensions")
+	}
+
+	result := &Result{
+		Blocks: make([]TextBlock, 0, len(order)),
+	}
+	for _, key := range order {
+		if len(words[key]) == 0 {
+			continue
+		}
+		block := TextBlock{
+			BoundingBox: lines[key].box(pageWidth, pageHeight),
+			Words:       make([]Word, len(words[key])),
+		}
+		texts := make([]string, len(words[key]))
+		var confSum float64
+		confCount := 0
+		for i, w := range words[key] {
+			block.Words[i] = Word{
+				Text:        w.text,
+				BoundingBox: w.box(pageWidth, pageHeight),
+				Confidence:  UnknownConfidence,
+			}
+			if w.conf >= 0 {
+				block.Words[i].Confidence = w.conf / 100
+				confSum += block.Words[i].Confidence
+				confCount++
+			}
+			texts[i] = w.text
+		}
+		block.Text = strings.Join(texts, " ")
+
+		// 行置信度取单词置信度的平均值
+		block.Confidence = UnknownConfidence
+		if confCount > 0 {
+			block.Confidence = confSum / float64(confCount)
+		}
+		result.Blocks = append(result.Blocks, block)
+	}
+
+	return result, nil
+}
+
+// box 返回按页面尺寸归一化的边界框。
+func (r *tsvRow) box(pageWidth, pageHeight float64) BoundingBox {
+	return BoundingBox{
+		X:      r.left / pageWidth,
+		Y:      r.top / pageHeight,
+		Width:  r.width / pageWidth,
+		Height: r.height / pageHeight,
+	}
+}
+
+// parseTSVRow 解析一行 TSV，表头和格式不正确的行返回 false。
+// 列顺序: level page_num block_num par_num line_num word_num left top width height conf text
+func parseTSVRow(s string) (*tsvRow, bool) {
+	fields := strings.SplitN(s, "\t", 12)
+	if len(fields) < 11 {
+		return nil, false
+	}
+
+	var ints [10]int
+	for i := range ints {
+		n, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return nil, false
+		}
+		ints[i] = n
+	}
+
+	row := &tsvRow{
+		level:  ints[0],
+		page:   ints[1],
+		block:  ints[2],
+		par:    ints[3],
+		line:   ints[4],
+		left:   float64(ints[6]),
+		top:    float64(ints[7]),
+		width:  float64(ints[8]),
+		height: float64(ints[9]),
+	}
+	// conf 可能是小数（tesseract 5），-1 表示该行不是单词
+	conf, err := strconv.ParseFloat(fields[10], 64)
+	if err != nil {
+		return nil, false
+	}
+	row.conf = conf
+	if len(fields) == 12 {
+		row.text = fields[11]
+	}
+	return row, true
+}
@@ -0,0 +1,183 @@
+//go:build linux
+
+package sysocr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cannedTSV 是 tesseract 5 的 TSV 输出：页面 200×100，第一行两个单词，
+// 第二行包含一个空单词和一个没有置信度的单词，第三行只有空单词。
+const cannedTSV = "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+	"1\t1\t0\t0\t0\t0\t0\t0\t200\t100\t-1\t\n" +
+	"2\t1\t1\t0\t0\t0\t10\t10\t180\t60\t-1\t\n" +
+	"3\t1\t1\t1\t0\t0\t10\t10\t180\t60\t-1\t\n" +
+	"4\t1\t1\t1\t1\t0\t10\t10\t100\t20\t-1\t\n" +
+	"5\t1\t1\t1\t1\t1\t10\t10\t40\t20\t96.5\tHello\n" +
+	"5\t1\t1\t1\t1\t2\t60\t10\t50\t20\t90\tworld\n" +
+	"4\t1\t1\t1\t2\t0\t10\t40\t60\t20\t-1\t\n" +
+	"5\t1\t1\t1\t2\t1\t10\t40\t10\t20\t95\t \n" +
+	"5\t1\t1\t1\t2\t2\t30\t40\t40\t20\t-1\tsecond\n" +
+	"4\t1\t1\t1\t3\t0\t10\t70\t60\t20\t-1\t\n" +
+	"5\t1\t1\t1\t3\t1\t10\t70\t60\t20\t95\t\n"
+
+func TestParseTesseractTSV(t *testing.T) {
+	result, err := parseTesseractTSV([]byte(cannedTSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Blocks) != 2 {
+		t.Fatalf("got %d blocks, want 2 (lines without words are dropped): %+v", len(result.Blocks), result.Blocks)
+	}
+
+	first := result.Blocks[0]
+	if first.Text != "Hello world" {
+		t.Errorf("first line text = %q", first.Text)
+	}
+	if want := (BoundingBox{X: 0.05, Y: 0.1, Width: 0.5, Height: 0.2}); !boxNear(first.BoundingBox, want) {
+		t.Errorf("first line box = %+v, want %+v", first.BoundingBox, want)
+	}
+	if len(first.Words) != 2 || first.Words[0].Text != "Hello" || first.Words[1].Text != "world" {
+		t.Fatalf("first line words = %+v", first.Words)
+	}
+	if !near(first.Words[0].Confidence, 0.965) || !near(first.Confidence, (0.965+0.9)/2) {
+		t.Errorf("confidence: word %v, line %v", first.Words[0].Confidence, first.Confidence)
+	}
+	if want := (BoundingBox{X: 0.3, Y: 0.1, Width: 0.25, Height: 0.2}); !boxNear(first.Words[1].BoundingBox, want) {
+		t.Errorf("second word box = %+v, want %+v", first.Words[1].BoundingBox, want)
+	}
+
+	second := result.Blocks[1]
+	if second.Text != "second" || len(second.Words) != 1 {
+		t.Fatalf("second line = %+v, want only the non-empty word", second)
+	}
+	if second.Words[0].Confidence != UnknownConfidence || second.Confidence != UnknownConfidence {
+		t.Errorf("conf -1 should be UnknownConfidence, got word %v, line %v",
+			second.Words[0].Confidence, second.Confidence)
+	}
+}
+
+func TestParseTesseractTSVMultiPage(t *testing.T) {
+	// 多页 TIFF：第二页尺寸不同，且 block/par/line 编号与第一页相同
+	tsv := cannedTSV +
+		"1\t2\t0\t0\t0\t0\t0\t0\t400\t400\t-1\t\n" +
+		"4\t2\t1\t1\t1\t0\t0\t0\t400\t40\t-1\t\n" +
+		"5\t2\t1\t1\t1\t1\t0\t0\t400\t40\t80\tpage2\n"
+	result, err := parseTesseractTSV([]byte(tsv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Blocks) != 2 || result.Blocks[0].Text != "Hello world" || result.Blocks[1].Text != "second" {
+		t.Fatalf("blocks = %+v, want only the first page", result.Blocks)
+	}
+	// 坐标仍按第一页的 200×100 归一化
+	if want := (BoundingBox{X: 0.05, Y: 0.1, Width: 0.5, Height: 0.2}); !boxNear(result.Blocks[0].BoundingBox, want) {
+		t.Errorf("first line box = %+v, want %+v", result.Blocks[0].BoundingBox, want)
+	}
+}
+
+func TestParseTesseractTSVNoPage(t *testing.T) {
+	tsv := "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+		"4\t1\t1\t1\t1\t0\t10\t10\t100\t20\t-1\t\n" +
+		"5\t1\t1\t1\t1\t1\t10\t10\t40\t20\t96\tHello\n"
+	if _, err := parseTesseractTSV([]byte(tsv)); err == nil {
+		t.Fatal("expected an error for output without a page row")
+	}
+	if _, err := parseTesseractTSV(nil); err == nil {
+		t.Fatal("expected an error for empty output")
+	}
+}
+
+func TestTesseractLanguages(t *testing.T) {
+	got := tesseractLanguages([]string{"en", "zh-Hans", "de-AT", "frk"})
+	if want := "eng+chi_sim+deu+frk"; got != want {
+		t.Fatalf("tesseractLanguages = %q, want %q", got, want)
+	}
+}
+
+// writeStub 在临时目录中写入一个 shell 脚本并返回其路径。
+func writeStub(t *testing.T, script string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tesseract")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTesseractEngineStub(t *testing.T) {
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	tsvFile := filepath.Join(dir, "out.tsv")
+	if err := os.WriteFile(tsvFile, []byte(cannedTSV), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// 记录参数和标准输入的字节数，然后输出预设的 TSV
+	stub := writeStub(t, `echo "$@" > '`+argsFile+`'
+wc -c >> '`+argsFile+`'
+cat '`+tsvFile+`'
+`)
+
+	engine := &TesseractEngine{Path: stub, Args: []string{"--psm", "6"}}
+	if !engine.Available() {
+		t.Fatal("Available() = false for an existing stub")
+	}
+	result, err := engine.Recognize(context.Background(), []byte("image"), EngineOptions{Languages: []string{"en", "ja"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Blocks) != 2 || result.Blocks[0].Text != "Hello world" {
+		t.Fatalf("blocks = %+v", result.Blocks)
+	}
+
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(args)), "\n")
+	if want := "stdin stdout -l eng+jpn --psm 6 tsv"; lines[0] != want {
+		t.Errorf("args = %q, want %q", lines[0], want)
+	}
+	if len(lines) < 2 || strings.TrimSpace(lines[1]) != "5" {
+		t.Errorf("stdin bytes = %q, want 5", lines[1:])
+	}
+}
+
+func TestTesseractEngineFailure(t *testing.T) {
+	stub := writeStub(t, "echo 'Error opening data file' >&2\nexit 1\n")
+	_, err := (&TesseractEngine{Path: stub}).Recognize(context.Background(), nil, EngineOptions{})
+	if err == nil || !strings.Contains(err.Error(), "Error opening data file") {
+		t.Fatalf("err = %v, want tesseract's stderr", err)
+	}
+}
+
+func TestTesseractEngineNotFound(t *testing.T) {
+	engine := &TesseractEngine{Path: "sysocr-test-no-such-tesseract"}
+	if engine.Available() {
+		t.Fatal("Available() = true for a missing executable")
+	}
+	_, err := engine.Recognize(context.Background(), nil, EngineOptions{})
+	if !errors.Is(err, ErrUnsupportedPlatform) {
+		t.Fatalf("err = %v, want ErrUnsupportedPlatform", err)
+	}
+}
+
+func TestTesseractEngineCanceled(t *testing.T) {
+	stub := writeStub(t, "exec sleep 10\n")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := (&TesseractEngine{Path: stub}).Recognize(ctx, nil, EngineOptions{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Recognize returned after %v, want soon after the deadline", elapsed)
+	}
+}
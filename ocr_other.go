@@ -0,0 +1,9 @@
+//go:build !darwin && !windows && !linux
+
+package sysocr
+
+// platformEngine 在没有原生 OCR 接口的平台上返回 nil，
+// 此时 Recognize 返回 ErrUnsupportedPlatform，除非调用方注册了自定义引擎。
+func platformEngine() Engine {
+	return nil
+}
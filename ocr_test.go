@@ -0,0 +1,131 @@
+package sysocr
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestMinConfidence(t *testing.T) {
+	blocks := []TextBlock{
+		{Text: "high", BoundingBox: BoundingBox{0.1, 0.1, 0.2, 0.1}, Confidence: 0.9},
+		{Text: "low", BoundingBox: BoundingBox{0.1, 0.3, 0.2, 0.1}, Confidence: 0.2},
+		{Text: "unknown", BoundingBox: BoundingBox{0.1, 0.5, 0.2, 0.1}, Confidence: UnknownConfidence},
+	}
+	registerTestEngine(t, &FakeEngine{EngineName: "test-conf", Result: &Result{Blocks: blocks}})
+	result, err := Recognize(Options{Engine: "test-conf", Input: Input{Data: testPNG(t, 10, 10)}, MinConfidence: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Text != "high\nunknown" {
+		t.Fatalf("Text = %q, want the low-confidence block dropped", result.Text)
+	}
+}
+
+func TestMinConfidenceUnsetConfidence(t *testing.T) {
+	// 预设结果没有设置置信度：不应被过滤，且报告为未知
+	blocks := []TextBlock{
+		{Text: "a", BoundingBox: BoundingBox{0.1, 0.1, 0.2, 0.1}, Words: []Word{{Text: "a"}}},
+		{Text: "b", BoundingBox: BoundingBox{0.1, 0.3, 0.2, 0.1}},
+	}
+	registerTestEngine(t, &FakeEngine{EngineName: "test-unset", Result: &Result{Blocks: blocks}})
+	result, err := Recognize(Options{Engine: "test-unset", Input: Input{Data: testPNG(t, 10, 10)}, MinConfidence: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Blocks) != 2 {
+		t.Fatalf("got %d blocks, want 2", len(result.Blocks))
+	}
+	if result.Blocks[0].Confidence != UnknownConfidence || result.Blocks[0].Words[0].Confidence != UnknownConfidence {
+		t.Fatalf("confidence = %v/%v, want UnknownConfidence", result.Blocks[0].Confidence, result.Blocks[0].Words[0].Confidence)
+	}
+}
+
+// noConfidenceEngine 是不提供置信度的引擎，模拟 Windows.Media.Ocr。
+type noConfidenceEngine struct{ FakeEngine }
+
+func (e *noConfidenceEngine) Capabilities() Capabilities { return Capabilities{Words: true} }
+
+func TestMinConfidenceWithoutCapability(t *testing.T) {
+	e := &noConfidenceEngine{FakeEngine{
+		EngineName: "test-noconf",
+		Func: func(ctx context.Context, data []byte, opts EngineOptions) (*Result, error) {
+			return &Result{Blocks: []TextBlock{{Text: "x", BoundingBox: BoundingBox{0.1, 0.1, 0.2, 0.1}}}}, nil
+		},
+	}}
+	registerTestEngine(t, e)
+	result, err := Recognize(Options{Engine: "test-noconf", Input: Input{Data: testPNG(t, 10, 10)}, MinConfidence: 0.9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Blocks) != 1 || result.Blocks[0].Confidence != UnknownConfidence {
+		t.Fatalf("blocks = %+v, want one block with UnknownConfidence", result.Blocks)
+	}
+}
+
+func TestRecognizeTextAngle(t *testing.T) {
+	// 200×100 的图片中，上边 80 像素宽、向下倾斜 8 像素，即 atan(0.1)；
+	// 没有四边形的文本块由边界框补全，角度为 0，不影响中位数
+	skewed := Quad{{0.1, 0.1}, {0.5, 0.18}, {0.5, 0.28}, {0.1, 0.2}}
+	blocks := []TextBlock{
+		{Text: "a", BoundingBox: skewed.Bounds(), Quad: skewed},
+		{Text: "b", BoundingBox: skewed.Bounds(), Quad: skewed},
+		{Text: "c", BoundingBox: BoundingBox{0.1, 0.5, 0.4, 0.1}},
+	}
+	registerTestEngine(t, &FakeEngine{EngineName: "test-angle", Result: &Result{Blocks: blocks}})
+	result, err := Recognize(Options{Engine: "test-angle", Input: Input{Data: testPNG(t, 200, 100)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := math.Atan(0.1) * 180 / math.Pi; !near(result.TextAngle, want) {
+		t.Errorf("TextAngle = %v, want %v", result.TextAngle, want)
+	}
+	if result.Blocks[0].Quad != skewed {
+		t.Errorf("engine quad replaced: %v", result.Blocks[0].Quad)
+	}
+}
+
+func TestRecognizeQuadBackfill(t *testing.T) {
+	box := BoundingBox{0.1, 0.2, 0.3, 0.1}
+	registerTestEngine(t, &FakeEngine{EngineName: "test-quad", Result: &Result{Blocks: []TextBlock{{Text: "a", BoundingBox: box}}}})
+	result, err := Recognize(Options{Engine: "test-quad", Input: Input{Data: testPNG(t, 200, 100)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.Blocks[0].Quad; got != box.Quad() {
+		t.Errorf("Quad = %v, want %v", got, box.Quad())
+	}
+	if result.TextAngle != 0 {
+		t.Errorf("TextAngle = %v, want 0 for axis-aligned boxes", result.TextAngle)
+	}
+}
+
+func TestMaxCandidates(t *testing.T) {
+	// 引擎忽略请求的数量，总是返回三个候选结果
+	candidates := []Candidate{{Text: "hi", Confidence: 0.9}, {Text: "hl", Confidence: 0.5}, {Text: "h1", Confidence: 0.2}}
+	blocks := []TextBlock{{Text: "hi", BoundingBox: BoundingBox{0.1, 0.1, 0.2, 0.1}, Confidence: 0.9, Candidates: candidates}}
+	registerTestEngine(t, &FakeEngine{EngineName: "test-candidates", Result: &Result{Blocks: blocks}})
+	for _, tt := range []struct {
+		max  int
+		want int
+	}{
+		{0, 0},
+		{-1, 0},
+		{1, 1},
+		{2, 2},
+		{5, 3},
+	} {
+		result, err := Recognize(Options{Engine: "test-candidates", Input: Input{Data: testPNG(t, 10, 10)}, MaxCandidates: tt.max})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := result.Blocks[0].Candidates
+		if !slices.Equal(got, candidates[:tt.want]) {
+			t.Errorf("MaxCandidates %d: candidates = %v, want the first %d", tt.max, got, tt.want)
+		}
+		if tt.want == 0 && got != nil {
+			t.Errorf("MaxCandidates %d: candidates = %#v, want nil", tt.max, got)
+		}
+	}
+}
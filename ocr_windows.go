@@ -3,8 +3,10 @@
 package sysocr
 
 import (
+	"context"
 	"errors"
-	"strings"
+	"fmt"
+	"sync"
 	"syscall"
 	"time"
 	"unsafe"
@@ -15,25 +17,68 @@ import (
 // 忽略未使用的语言参数（暂时使用用户配置语言）
 var _ = func(languages []string) {}
 
-// Recognize 对提供的图片进行 OCR 识别。
-func Recognize(opts Options) (*Result, error) {
-	// 将输入转换为字节数据
-	data, err := resolveInput(opts.Input)
-	if err != nil {
-		return nil, err
+// platformEngine 返回 Windows 的默认引擎。
+func platformEngine() Engine {
+	return winrtEngine{}
+}
+
+// winrtEngine 使用 Windows.Media.Ocr 进行识别。
+type winrtEngine struct{}
+
+func (winrtEngine) Name() string { return "winrt" }
+
+func (winrtEngine) Capabilities() Capabilities {
+	// 暂时使用用户配置语言，不支持语言提示
+	return Capabilities{
+		Words:             true,
+		MaxImageDimension: winrtMaxImageDimension(),
 	}
+}
+
+var (
+	maxDimensionOnce sync.Once
+	maxDimension     int
+)
 
+// winrtMaxImageDimension 查询 OcrEngine.MaxImageDimension，查询失败时返回 0（不限制）。
+func winrtMaxImageDimension() int {
+	maxDimensionOnce.Do(func() {
+		if err := winrt.Initialize(); err != nil {
+			return
+		}
+		ocrStatics, err := winrt.GetOcrEngineStatics()
+		if err != nil {
+			return
+		}
+		defer ocrStatics.Release()
+		maxDimension = int(ocrStatics.GetMaxImageDimension())
+	})
+	return maxDimension
+}
+
+func (winrtEngine) Recognize(ctx context.Context, data []byte, opts EngineOptions) (*Result, error) {
 	// 初始化 Windows Runtime
 	if err := winrt.Initialize(); err != nil {
 		return nil, err
 	}
 
 	// 执行 OCR
-	return recognizeWithWinRT(data, opts.Languages)
+	return recognizeWithWinRT(ctx, data, opts.Languages)
+}
+
+// waitAsync 等待 WinRT 异步操作完成。
+// ctx 没有截止时间时使用 fallback 作为超时上限，避免异步操作异常时永久阻塞。
+func waitAsync(ctx context.Context, op *winrt.IAsyncOperation, fallback time.Duration) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, fallback)
+		defer cancel()
+	}
+	return op.Wait(ctx)
 }
 
 // recognizeWithWinRT 使用 Windows Runtime API 进行 OCR
-func recognizeWithWinRT(data []byte, languages []string) (*Result, error) {
+func recognizeWithWinRT(ctx context.Context, data []byte, languages []string) (*Result, error) {
 	// 创建 InMemoryRandomAccessStream
 	stream, err := winrt.CreateInMemoryRandomAccessStream()
 	if err != nil {
@@ -62,8 +107,8 @@ func recognizeWithWinRT(data []byte, languages []string) (*Result, error) {
 	if err != nil {
 		return nil, errors.New("failed to store data: " + err.Error())
 	}
-	if err := storeOp.Wait(30 * time.Second); err != nil {
-		return nil, errors.New("store operation timeout: " + err.Error())
+	if err := waitAsync(ctx, storeOp, 30*time.Second); err != nil {
+		return nil, fmt.Errorf("store operation failed: %w", err)
 	}
 	storeOp.Release()
 
@@ -72,8 +117,8 @@ func recognizeWithWinRT(data []byte, languages []string) (*Result, error) {
 	if err != nil {
 		return nil, errors.New("failed to flush: " + err.Error())
 	}
-	if err := flushOp.Wait(30 * time.Second); err != nil {
-		return nil, errors.New("flush operation timeout: " + err.Error())
+	if err := waitAsync(ctx, flushOp, 30*time.Second); err != nil {
+		return nil, fmt.Errorf("flush operation failed: %w", err)
 	}
 	flushOp.Release()
 
@@ -102,8 +147,8 @@ func recognizeWithWinRT(data []byte, languages []string) (*Result, error) {
 	}
 	defer createOp.Release()
 
-	if err := createOp.Wait(30 * time.Second); err != nil {
-		return nil, errors.New("BitmapDecoder creation timeout: " + err.Error())
+	if err := waitAsync(ctx, createOp, 30*time.Second); err != nil {
+		return nil, fmt.Errorf("BitmapDecoder creation failed: %w", err)
 	}
 
 	decoderInsp, err := createOp.GetResults()
@@ -133,8 +178,8 @@ func recognizeWithWinRT(data []byte, languages []string) (*Result, error) {
 	}
 	defer getBitmapOp.Release()
 
-	if err := getBitmapOp.Wait(30 * time.Second); err != nil {
-		return nil, errors.New("GetSoftwareBitmap timeout: " + err.Error())
+	if err := waitAsync(ctx, getBitmapOp, 30*time.Second); err != nil {
+		return nil, fmt.Errorf("GetSoftwareBitmap failed: %w", err)
 	}
 
 	bitmapInsp, err := getBitmapOp.GetResults()
@@ -167,8 +212,8 @@ func recognizeWithWinRT(data []byte, languages []string) (*Result, error) {
 	}
 	defer recognizeOp.Release()
 
-	if err := recognizeOp.Wait(60 * time.Second); err != nil {
-		return nil, errors.New("OCR timeout: " + err.Error())
+	if err := waitAsync(ctx, recognizeOp, 60*time.Second); err != nil {
+		return nil, fmt.Errorf("OCR failed: %w", err)
 	}
 
 	ocrResultInsp, err := recognizeOp.GetResults()
@@ -183,8 +228,9 @@ func recognizeWithWinRT(data []byte, languages []string) (*Result, error) {
 	result := &Result{
 		Blocks: make([]TextBlock, 0),
 	}
-
-	var textBuilder strings.Builder
+	if angle, ok := ocrResult.GetTextAngle(); ok {
+		result.TextAngle = angle
+	}
 
 	// 获取所有行
 	lines, err := ocrResult.GetLines()
@@ -216,6 +262,7 @@ func recognizeWithWinRT(data []byte, languages []string) (*Result, error) {
 		var minX, minY, maxX, maxY float32
 		first := true
 		wordCount := words.GetSize()
+		lineWords := make([]Word, 0, wordCount)
 		for j := uint32(0); j < wordCount; j++ {
 			wordInsp, err := words.GetAt(j)
 			if err != nil || wordInsp == nil {
@@ -224,6 +271,17 @@ func recognizeWithWinRT(data []byte, languages []string) (*Result, error) {
 
 			word := (*winrt.IOcrWord)(unsafe.Pointer(wordInsp))
 			rect := word.GetBoundingRect()
+			lineWords = append(lineWords, Word{
+				Text: word.GetText(),
+				BoundingBox: BoundingBox{
+					X:      float64(rect.X) / imageWidth,
+					Y:      float64(rect.Y) / imageHeight,
+					Width:  float64(rect.Width) / imageWidth,
+					Height: float64(rect.Height) / imageHeight,
+				},
+				// Windows.Media.Ocr 不提供置信度
+				Confidence: UnknownConfidence,
+			})
 
 			if first {
 				minX = rect.X
@@ -261,19 +319,15 @@ func recognizeWithWinRT(data []byte, languages []string) (*Result, error) {
 					Width:  float64(maxX-minX) / imageWidth,
 					Height: float64(maxY-minY) / imageHeight,
 				},
+				Words:      lineWords,
+				Confidence: UnknownConfidence,
 			}
 			result.Blocks = append(result.Blocks, block)
-
-			if textBuilder.Len() > 0 {
-				textBuilder.WriteString("\n")
-			}
-			textBuilder.WriteString(lineText)
 		}
 
 		line.Release()
 	}
 
-	result.Text = textBuilder.String()
 	return result, nil
 }
 
@@ -0,0 +1,220 @@
+package sysocr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"image/jpeg"
+)
+
+// Orientation 是 EXIF 方向标签 (0x0112) 的值，描述存储的像素需要如何变换才能正确显示。
+type Orientation int
+
+const (
+	OrientationNormal     Orientation = 1 // 无需变换
+	OrientationFlipH      Orientation = 2 // 水平翻转
+	OrientationRotate180  Orientation = 3 // 旋转 180°
+	OrientationFlipV      Orientation = 4 // 垂直翻转
+	OrientationTranspose  Orientation = 5 // 沿左上-右下对角线翻转
+	OrientationRotate90   Orientation = 6 // 顺时针旋转 90°
+	OrientationTransverse Orientation = 7 // 沿右上-左下对角线翻转
+	OrientationRotate270  Orientation = 8 // 顺时针旋转 270°
+)
+
+// swapsAxes 报告变换后宽高是否互换。
+func (o Orientation) swapsAxes() bool {
+	return o >= OrientationTranspose && o <= OrientationRotate270
+}
+
+// needsTransform 报告是否需要变换像素。
+func (o Orientation) needsTransform() bool {
+	return o >= OrientationFlipH && o <= OrientationRotate270
+}
+
+// toOriginal 将显示方向下的归一化坐标 (u, v) 映射回原始文件中存储的像素方向。
+func (o Orientation) toOriginal(u, v float64) (x, y float64) {
+	switch o {
+	case OrientationFlipH:
+		return 1 - u, v
+	case OrientationRotate180:
+		return 1 - u, 1 - v
+	case OrientationFlipV:
+		return u, 1 - v
+	case OrientationTranspose:
+		return v, u
+	case OrientationRotate90:
+		return v, 1 - u
+	case OrientationTransverse:
+		return 1 - v, 1 - u
+	case OrientationRotate270:
+		return 1 - v, u
+	}
+	return u, v
+}
+
+// ToOriginal 将识别结果中的边界框（基于方向校正后的图片）映射回原始文件的像素方向。
+// 用于 Result.Orientation 不为 0 时，在未经旋转的原图上绘制或裁剪。
+func (o Orientation) ToOriginal(b BoundingBox) BoundingBox {
+	x1, y1 := o.toOriginal(b.X, b.Y)
+	x2, y2 := o.toOriginal(b.X+b.Width, b.Y+b.Height)
+	return BoundingBox{
+		X:      min(x1, x2),
+		Y:      min(y1, y2),
+		Width:  max(x1, x2) - min(x1, x2),
+		Height: max(y1, y2) - min(y1, y2),
+	}
+}
+
+// ToOriginalQuad 与 ToOriginal 相同，但作用于四边形的每个角点，角点顺序保持不变。
+func (o Orientation) ToOriginalQuad(q Quad) Quad {
+	var out Quad
+	for i, p := range q {
+		out[i].X, out[i].Y = o.toOriginal(p.X, p.Y)
+	}
+	return out
+}
+
+// jpegOrientation 读取 JPEG APP1 段中 EXIF 的方向标签，没有或无效时返回 OrientationNormal。
+func jpegOrientation(data []byte) Orientation {
+	orientation := OrientationNormal
+	_ = walkJPEG(data, func(marker byte, segment []byte) (bool, error) {
+		if marker != 0xe1 || !bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return false, nil
+		}
+		if o, ok := exifOrientation(segment[6:]); ok {
+			orientation = o
+		}
+		return true, nil
+	})
+	return orientation
+}
+
+// exifOrientation 在 EXIF 的 TIFF 结构中查找 IFD0 的方向标签。
+func exifOrientation(tiff []byte) (Orientation, bool) {
+	if len(tiff) < 8 {
+		return 0, false
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:4]) {
+	case "II*\x00":
+		order = binary.LittleEndian
+	case "MM\x00*":
+		order = binary.BigEndian
+	default:
+		return 0, false
+	}
+
+	offset := int64(order.Uint32(tiff[4:8]))
+	if offset+2 > int64(len(tiff)) {
+		return 0, false
+	}
+	count := int64(order.Uint16(tiff[offset : offset+2]))
+	entries := offset + 2
+	for n := int64(0); n < count; n++ {
+		start := entries + n*12
+		if start+12 > int64(len(tiff)) {
+			return 0, false
+		}
+		entry := tiff[start : start+12]
+		if order.Uint16(entry[0:2]) != 0x0112 {
+			continue
+		}
+		// 类型必须为 SHORT
+		if order.Uint16(entry[2:4]) != 3 {
+			return 0, false
+		}
+		o := Orientation(order.Uint16(entry[8:10]))
+		if o < OrientationNormal || o > OrientationRotate270 {
+			return 0, false
+		}
+		return o, true
+	}
+	return 0, false
+}
+
+// normalizeOrientation 按 EXIF 方向旋转/翻转 JPEG 图片，返回以无损格式编码的新数据。
+// 不需要变换时返回原数据，方向为 0；Go 无法解码的 JPEG（如算术编码、12 位精度）同样返回原数据，
+// 由后端自行解码，坐标基于原文件的像素方向。
+func normalizeOrientation(data []byte, info ImageInfo) ([]byte, ImageInfo, Orientation, error) {
+	if info.Format != FormatJPEG {
+		return data, info, 0, nil
+	}
+	o := jpegOrientation(data)
+	if !o.needsTransform() {
+		return data, info, 0, nil
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return data, info, 0, nil
+	}
+	oriented, err := encodeImage(orientImage(img, o))
+	if err != nil {
+		return nil, ImageInfo{}, 0, err
+	}
+
+	info.Format = FormatPNG
+	if o.swapsAxes() {
+		info.Width, info.Height = info.Height, info.Width
+	}
+	return oriented, info, o, nil
+}
+
+// orientImage 按方向 o 变换图片像素。灰度图保持为 *image.Gray，其他图片转换为 *image.RGBA。
+func orientImage(img image.Image, o Orientation) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if o.swapsAxes() {
+		dw, dh = h, w
+	}
+
+	// dst 返回原图像素 (x, y) 在变换后图片中的位置
+	dst := func(x, y int) (int, int) {
+		switch o {
+		case OrientationFlipH:
+			return w - 1 - x, y
+		case OrientationRotate180:
+			return w - 1 - x, h - 1 - y
+		case OrientationFlipV:
+			return x, h - 1 - y
+		case OrientationTranspose:
+			return y, x
+		case OrientationRotate90:
+			return h - 1 - y, x
+		case OrientationTransverse:
+			return h - 1 - y, w - 1 - x
+		case OrientationRotate270:
+			return y, w - 1 - x
+		}
+		return x, y
+	}
+
+	if gray, ok := img.(*image.Gray); ok {
+		out := image.NewGray(image.Rect(0, 0, dw, dh))
+		for y := 0; y < h; y++ {
+			row := gray.Pix[y*gray.Stride : y*gray.Stride+w]
+			for x, v := range row {
+				dx, dy := dst(x, y)
+				out.Pix[dy*out.Stride+dx] = v
+			}
+		}
+		return out
+	}
+
+	src, ok := img.(*image.RGBA)
+	if !ok || b.Min != (image.Point{}) {
+		src = image.NewRGBA(image.Rect(0, 0, w, h))
+		draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	}
+	out := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		row := src.Pix[y*src.Stride : y*src.Stride+w*4]
+		for x := 0; x < w; x++ {
+			dx, dy := dst(x, y)
+			copy(out.Pix[dy*out.Stride+dx*4:dy*out.Stride+dx*4+4], row[x*4:x*4+4])
+		}
+	}
+	return out
+}
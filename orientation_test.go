@@ -0,0 +1,263 @@
+package sysocr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// exifTIFF 返回只有一个 IFD0 条目的 EXIF TIFF 结构。
+func exifTIFF(order binary.AppendByteOrder, tag, typ, value uint16) []byte {
+	var tiff []byte
+	if order == binary.LittleEndian {
+		tiff = []byte("II*\x00")
+	} else {
+		tiff = []byte("MM\x00*")
+	}
+	tiff = order.AppendUint32(tiff, 8)
+	tiff = order.AppendUint16(tiff, 1)
+	tiff = order.AppendUint16(tiff, tag)
+	tiff = order.AppendUint16(tiff, typ)
+	tiff = order.AppendUint32(tiff, 1)
+	tiff = order.AppendUint16(tiff, value)
+	tiff = order.AppendUint16(tiff, 0)
+	return order.AppendUint32(tiff, 0) // 没有下一个 IFD
+}
+
+// withExif 在 JPEG 的 SOI 之后插入包含 tiff 的 APP1 段。
+func withExif(jpg, tiff []byte) []byte {
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := binary.BigEndian.AppendUint16([]byte{0xff, 0xe1}, uint16(len(payload)+2))
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, segment...)
+	out = append(out, payload...)
+	return append(out, jpg[2:]...)
+}
+
+// testJPEG 返回一张 16×8 的 JPEG：左半边黑色，右半边白色，按 8×8 块对齐以避免压缩误差。
+func testJPEG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, 16, 8))
+	for y := 0; y < 8; y++ {
+		for x := 8; x < 16; x++ {
+			img.SetGray(x, y, color.Gray{255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExifOrientation(t *testing.T) {
+	le, be := binary.LittleEndian, binary.BigEndian
+	for _, tt := range []struct {
+		name string
+		tiff []byte
+		want Orientation
+		ok   bool
+	}{
+		{"little endian", exifTIFF(le, 0x0112, 3, 6), OrientationRotate90, true},
+		{"big endian", exifTIFF(be, 0x0112, 3, 8), OrientationRotate270, true},
+		{"normal", exifTIFF(le, 0x0112, 3, 1), OrientationNormal, true},
+		{"other tag", exifTIFF(le, 0x010f, 3, 6), 0, false},
+		{"not SHORT", exifTIFF(le, 0x0112, 4, 6), 0, false},
+		{"zero", exifTIFF(be, 0x0112, 3, 0), 0, false},
+		{"out of range", exifTIFF(be, 0x0112, 3, 9), 0, false},
+		{"bad byte order", append([]byte("XX"), exifTIFF(le, 0x0112, 3, 6)[2:]...), 0, false},
+		{"truncated entry", exifTIFF(le, 0x0112, 3, 6)[:15], 0, false},
+		{"bad offset", append(exifTIFF(le, 0x0112, 3, 6)[:4], 0xff, 0xff, 0, 0), 0, false},
+		{"short", []byte("II*"), 0, false},
+	} {
+		o, ok := exifOrientation(tt.tiff)
+		if o != tt.want || ok != tt.ok {
+			t.Errorf("%s: exifOrientation = %d, %v, want %d, %v", tt.name, o, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	jpg := testJPEG(t)
+	if o := jpegOrientation(jpg); o != OrientationNormal {
+		t.Errorf("JPEG without EXIF: %d, want OrientationNormal", o)
+	}
+	for o := OrientationNormal; o <= OrientationRotate270; o++ {
+		if got := jpegOrientation(withExif(jpg, exifTIFF(binary.BigEndian, 0x0112, 3, uint16(o)))); got != o {
+			t.Errorf("jpegOrientation = %d, want %d", got, o)
+		}
+	}
+	// 不是 EXIF 的 APP1 段（如 XMP）被忽略
+	xmp := withExif(jpg, nil)
+	copy(xmp[6:], "XMP\x00\x00\x00")
+	if o := jpegOrientation(xmp); o != OrientationNormal {
+		t.Errorf("APP1 without EXIF: %d, want OrientationNormal", o)
+	}
+}
+
+// orientTestImage 返回 3×2 的图片，每个像素的值各不相同。
+func orientTestImage() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 3, 2))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i + 1)
+	}
+	return img
+}
+
+func TestOrientImage(t *testing.T) {
+	// 3×2 图片
+	//   1 2 3
+	//   4 5 6
+	// 按显示方向变换后的像素，每行一个字符串
+	for _, tt := range []struct {
+		o    Orientation
+		want []string
+	}{
+		{OrientationNormal, []string{"123", "456"}},
+		{OrientationFlipH, []string{"321", "654"}},
+		{OrientationRotate180, []string{"654", "321"}},
+		{OrientationFlipV, []string{"456", "123"}},
+		{OrientationTranspose, []string{"14", "25", "36"}},
+		{OrientationRotate90, []string{"41", "52", "63"}},
+		{OrientationTransverse, []string{"63", "52", "41"}},
+		{OrientationRotate270, []string{"36", "25", "14"}},
+	} {
+		gray := orientTestImage()
+		// 灰度图和其他类型的图片走不同的路径
+		for _, src := range []image.Image{gray, image.NewPaletted(gray.Rect, nil), image.NewRGBA(gray.Rect)} {
+			if p, ok := src.(*image.Paletted); ok {
+				for i := range 6 {
+					p.Palette = append(p.Palette, color.Gray{uint8(i + 1)})
+				}
+				copy(p.Pix, []uint8{0, 1, 2, 3, 4, 5})
+			} else if rgba, ok := src.(*image.RGBA); ok {
+				for y := 0; y < 2; y++ {
+					for x := 0; x < 3; x++ {
+						rgba.Set(x, y, gray.At(x, y))
+					}
+				}
+			}
+			out := orientImage(src, tt.o)
+			if out.Bounds().Min != (image.Point{}) || out.Bounds().Dy() != len(tt.want) || out.Bounds().Dx() != len(tt.want[0]) {
+				t.Fatalf("orientation %d (%T): bounds %v", tt.o, src, out.Bounds())
+			}
+			for y, row := range tt.want {
+				for x := range row {
+					want := row[x] - '0'
+					if got := color.GrayModel.Convert(out.At(x, y)).(color.Gray).Y; got != want {
+						t.Errorf("orientation %d (%T): pixel (%d, %d) = %d, want %d", tt.o, src, x, y, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestOrientImageSubImage(t *testing.T) {
+	// 原点不在 (0, 0) 的图片
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(2, 1, color.White)
+	sub := img.SubImage(image.Rect(1, 1, 4, 3))
+	out := orientImage(sub, OrientationRotate180)
+	if out.Bounds() != image.Rect(0, 0, 3, 2) || color.GrayModel.Convert(out.At(1, 1)).(color.Gray).Y != 255 {
+		t.Fatalf("bounds %v, pixel (1, 1) = %v", out.Bounds(), out.At(1, 1))
+	}
+}
+
+func TestToOriginal(t *testing.T) {
+	// 与 orientImage 一致：显示方向中一个像素的边界框映射回原图中对应的像素
+	src := orientTestImage()
+	for o := OrientationNormal; o <= OrientationRotate270; o++ {
+		out := orientImage(src, o).(*image.Gray)
+		w, h := out.Bounds().Dx(), out.Bounds().Dy()
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				box := BoundingBox{X: float64(x) / float64(w), Y: float64(y) / float64(h), Width: 1 / float64(w), Height: 1 / float64(h)}
+				orig := o.ToOriginal(box)
+				px := orig.Pixels(3, 2)
+				if px.Dx() != 1 || px.Dy() != 1 || src.GrayAt(px.Min.X, px.Min.Y) != out.GrayAt(x, y) {
+					t.Errorf("orientation %d: pixel (%d, %d) maps to %v", o, x, y, px)
+					continue
+				}
+
+				// ToOriginalQuad 映射每个角点，外接矩形与 ToOriginal 相同
+				q := o.ToOriginalQuad(box.Quad())
+				if b := polygonBounds(q[:]); !boxNear(b, orig) {
+					t.Errorf("orientation %d: quad bounds %+v, want %+v", o, b, orig)
+				}
+			}
+		}
+	}
+	// 角点顺序保持不变：旋转 90° 后的左上角来自原图的左下角
+	q := OrientationRotate90.ToOriginalQuad(BoundingBox{0, 0, 1, 1}.Quad())
+	if q[0] != (Point{0, 1}) || q[1] != (Point{0, 0}) || q[2] != (Point{1, 0}) || q[3] != (Point{1, 1}) {
+		t.Errorf("ToOriginalQuad = %v", q)
+	}
+}
+
+func TestNormalizeOrientation(t *testing.T) {
+	jpg := withExif(testJPEG(t), exifTIFF(binary.LittleEndian, 0x0112, 3, uint16(OrientationRotate90)))
+	info, err := DetectImage(jpg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, info, o, err := normalizeOrientation(jpg, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o != OrientationRotate90 || info != (ImageInfo{Format: FormatPNG, Width: 8, Height: 16}) {
+		t.Fatalf("normalizeOrientation = %+v, %d", info, o)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 顺时针旋转 90° 后，原图左边的黑色在上，右边的白色在下
+	top := color.GrayModel.Convert(img.At(4, 4)).(color.Gray).Y
+	bottom := color.GrayModel.Convert(img.At(4, 12)).(color.Gray).Y
+	if img.Bounds().Dx() != 8 || img.Bounds().Dy() != 16 || top > 16 || bottom < 240 {
+		t.Fatalf("rotated image %v: top %d, bottom %d", img.Bounds(), top, bottom)
+	}
+
+	// 不需要变换时返回原数据
+	plain := testJPEG(t)
+	info, _ = DetectImage(plain)
+	if data, _, o, err := normalizeOrientation(plain, info); err != nil || o != 0 || !bytes.Equal(data, plain) {
+		t.Fatalf("JPEG without EXIF: orientation %d, err %v", o, err)
+	}
+}
+
+func TestNormalizeOrientationUndecodable(t *testing.T) {
+	// 将 SOF0 改为 SOF9（算术编码），Go 的 jpeg 包不支持，但后端可能能够解码
+	jpg := withExif(testJPEG(t), exifTIFF(binary.LittleEndian, 0x0112, 3, uint16(OrientationRotate90)))
+	sof := bytes.Index(jpg, []byte{0xff, 0xc0})
+	if sof < 0 {
+		t.Fatal("no SOF0 marker")
+	}
+	jpg[sof+1] = 0xc9
+	info, err := DetectImage(jpg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, got, o, err := normalizeOrientation(jpg, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o != 0 || got != info || !bytes.Equal(data, jpg) {
+		t.Fatalf("normalizeOrientation = %+v, %d, want the original data with orientation 0", got, o)
+	}
+
+	registerTestEngine(t, &FakeEngine{EngineName: "test-sof9", Result: &Result{}})
+	result, err := Recognize(Options{Engine: "test-sof9", Input: Input{Data: jpg}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Orientation != 0 || result.ImageWidth != 16 || result.ImageHeight != 8 {
+		t.Fatalf("result = %dx%d, orientation %d", result.ImageWidth, result.ImageHeight, result.Orientation)
+	}
+}
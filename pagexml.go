@@ -0,0 +1,301 @@
+package sysocr
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	pageNamespace      = "http://schema.primaresearch.org/PAGE/gts/pagecontent/2019-07-15"
+	pageSchemaLocation = pageNamespace + " " + pageNamespace + "/pagecontent.xsd"
+)
+
+// pageDocument 是 PAGE XML（2019-07-15）的文档结构，读写共用。
+type pageDocument struct {
+	XMLName        xml.Name     `xml:"PcGts"`
+	Xmlns          string       `xml:"xmlns,attr,omitempty"`
+	XmlnsXsi       string       `xml:"xmlns:xsi,attr,omitempty"`
+	SchemaLocation string       `xml:"xsi:schemaLocation,attr,omitempty"`
+	Metadata       pageMetadata `xml:"Metadata"`
+	Page           pagePage     `xml:"Page"`
+}
+
+type pageMetadata struct {
+	Creator    string `xml:"Creator"`
+	Created    string `xml:"Created"`
+	LastChange string `xml:"LastChange"`
+}
+
+type pagePage struct {
+	ImageFilename string           `xml:"imageFilename,attr"`
+	ImageWidth    int              `xml:"imageWidth,attr"`
+	ImageHeight   int              `xml:"imageHeight,attr"`
+	Orientation   *float64         `xml:"orientation,attr,omitempty"`
+	Regions       []pageTextRegion `xml:"TextRegion"`
+}
+
+type pageTextRegion struct {
+	ID        string           `xml:"id,attr"`
+	Type      string           `xml:"type,attr,omitempty"`
+	Coords    pageCoords       `xml:"Coords"`
+	Regions   []pageTextRegion `xml:"TextRegion"` // 嵌套的区域，仅在读取时出现
+	Lines     []pageTextLine   `xml:"TextLine"`
+	TextEquiv []pageTextEquiv  `xml:"TextEquiv"`
+}
+
+type pageTextLine struct {
+	ID        string          `xml:"id,attr"`
+	Coords    pageCoords      `xml:"Coords"`
+	Words     []pageWord      `xml:"Word"`
+	TextEquiv []pageTextEquiv `xml:"TextEquiv"`
+}
+
+type pageWord struct {
+	ID        string          `xml:"id,attr"`
+	Coords    pageCoords      `xml:"Coords"`
+	TextEquiv []pageTextEquiv `xml:"TextEquiv"`
+}
+
+type pageCoords struct {
+	Points string `xml:"points,attr"`
+}
+
+type pageTextEquiv struct {
+	Index   int    `xml:"index,attr,omitempty"`
+	Conf    string `xml:"conf,attr,omitempty"`
+	Unicode string `xml:"Unicode"`
+}
+
+// WritePAGE 以 PAGE XML（2019-07-15 版本）格式写出识别结果，坐标单位为像素。
+//
+// 每个段落写为 type="paragraph" 的 TextRegion，行的 Coords 使用 TextBlock.Quad，
+// 单词写为 Word（引擎不提供时不写出）。置信度已知时写入 TextEquiv 的 conf 属性，
+// TextBlock.Candidates 按顺序写为带 index 的多个 TextEquiv；Result.TextAngle 写为 Page 的 orientation。
+// imageWidth、imageHeight 为 0 时使用 Result.ImageWidth、Result.ImageHeight。
+func (r *Result) WritePAGE(w io.Writer, imageWidth, imageHeight int) error {
+	imageWidth, imageHeight, err := r.outputSize(imageWidth, imageHeight)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	doc := pageDocument{
+		Xmlns:          pageNamespace,
+		XmlnsXsi:       xsiNamespace,
+		SchemaLocation: pageSchemaLocation,
+		Metadata:       pageMetadata{Creator: "sysocr", Created: now, LastChange: now},
+		Page:           pagePage{ImageWidth: imageWidth, ImageHeight: imageHeight},
+	}
+	if r.TextAngle != 0 {
+		// orientation 是校正倾斜需要的顺时针旋转角度，与 TextAngle 方向相反
+		orientation := -r.TextAngle
+		doc.Page.Orientation = &orientation
+	}
+
+	points := func(q Quad) pageCoords {
+		parts := make([]string, len(q))
+		for i, p := range q {
+			x := int(math.Round(p.X * float64(imageWidth)))
+			y := int(math.Round(p.Y * float64(imageHeight)))
+			parts[i] = strconv.Itoa(x) + "," + strconv.Itoa(y)
+		}
+		return pageCoords{Points: strings.Join(parts, " ")}
+	}
+	rect := func(b BoundingBox) pageCoords {
+		p := b.Pixels(imageWidth, imageHeight)
+		return pageCoords{Points: fmt.Sprintf("%d,%d %d,%d %d,%d %d,%d",
+			p.Min.X, p.Min.Y, p.Max.X, p.Min.Y, p.Max.X, p.Max.Y, p.Min.X, p.Max.Y)}
+	}
+
+	regionID := 0
+	for _, region := range r.layout(imageWidth, imageHeight).Regions {
+		for _, para := range region.Paragraphs {
+			regionID++
+			id := "r" + strconv.Itoa(regionID)
+			tr := pageTextRegion{ID: id, Type: "paragraph", Coords: rect(para.BoundingBox)}
+			texts := make([]string, 0, len(para.Lines))
+			for i, l := range para.Lines {
+				lineID := id + "l" + strconv.Itoa(i+1)
+				quad := l.Quad
+				if quad.IsZero() {
+					quad = l.BoundingBox.Quad()
+				}
+				line := pageTextLine{ID: lineID, Coords: points(quad), TextEquiv: pageLineEquiv(l)}
+				for j, word := range l.Words {
+					line.Words = append(line.Words, pageWord{
+						ID:        lineID + "w" + strconv.Itoa(j+1),
+						Coords:    rect(word.BoundingBox),
+						TextEquiv: []pageTextEquiv{newPageTextEquiv(0, word.Text, word.Confidence)},
+					})
+				}
+				tr.Lines = append(tr.Lines, line)
+				texts = append(texts, l.Text)
+			}
+			tr.TextEquiv = []pageTextEquiv{{Unicode: strings.Join(texts, "\n")}}
+			doc.Page.Regions = append(doc.Page.Regions, tr)
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", " ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// pageLineEquiv 返回一行的 TextEquiv，有候选结果时每个候选一个并带 index。
+func pageLineEquiv(b TextBlock) []pageTextEquiv {
+	if len(b.Candidates) == 0 {
+		return []pageTextEquiv{newPageTextEquiv(0, b.Text, b.Confidence)}
+	}
+	equivs := make([]pageTextEquiv, len(b.Candidates))
+	for i, c := range b.Candidates {
+		equivs[i] = newPageTextEquiv(i+1, c.Text, c.Confidence)
+	}
+	return equivs
+}
+
+func newPageTextEquiv(index int, text string, conf float64) pageTextEquiv {
+	e := pageTextEquiv{Index: index, Unicode: text}
+	if conf >= 0 {
+		e.Conf = formatConfidence(conf)
+	}
+	return e
+}
+
+// ReadPAGE 从 PAGE XML 文档读取识别结果。
+//
+// 每个 TextLine（包括嵌套区域中的）按出现顺序成为一个 TextBlock，Word 成为 Word。
+// 恰好 4 个点的 Coords 作为 Quad，其余多边形取外接矩形；多个 TextEquiv 按 index 排序后
+// 第一个作为文本，全部作为 Candidates。坐标按 Page 的 imageWidth、imageHeight 归一化。
+func ReadPAGE(r io.Reader) (*Result, error) {
+	var doc pageDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("sysocr: decode PAGE: %w", err)
+	}
+	page := doc.Page
+	if page.ImageWidth <= 0 || page.ImageHeight <= 0 {
+		return nil, errors.New("sysocr: decode PAGE: page has no imageWidth/imageHeight")
+	}
+
+	result := &Result{ImageWidth: page.ImageWidth, ImageHeight: page.ImageHeight}
+	if page.Orientation != nil {
+		result.TextAngle = -*page.Orientation
+	}
+	w, h := float64(page.ImageWidth), float64(page.ImageHeight)
+
+	var walk func([]pageTextRegion) error
+	walk = func(regions []pageTextRegion) error {
+		for _, region := range regions {
+			if err := walk(region.Regions); err != nil {
+				return err
+			}
+			for _, line := range region.Lines {
+				block, err := pageBlock(line, w, h)
+				if err != nil {
+					return err
+				}
+				result.Blocks = append(result.Blocks, block)
+			}
+		}
+		return nil
+	}
+	if err := walk(page.Regions); err != nil {
+		return nil, err
+	}
+	result.finish()
+	return result, nil
+}
+
+// pageBlock 将 PAGE 的 TextLine 转换为 TextBlock。
+func pageBlock(line pageTextLine, w, h float64) (TextBlock, error) {
+	poly, err := parsePagePoints(line.Coords.Points, w, h)
+	if err != nil {
+		return TextBlock{}, err
+	}
+	block := TextBlock{Confidence: UnknownConfidence}
+	if len(poly) == 4 {
+		block.Quad = Quad(poly)
+	}
+	block.BoundingBox = polygonBounds(poly)
+
+	equivs := line.TextEquiv
+	sort.SliceStable(equivs, func(i, j int) bool { return equivs[i].Index < equivs[j].Index })
+	for _, e := range equivs {
+		block.Candidates = append(block.Candidates, Candidate{Text: e.Unicode, Confidence: parsePageConf(e.Conf)})
+	}
+	if len(block.Candidates) > 0 {
+		block.Text, block.Confidence = block.Candidates[0].Text, block.Candidates[0].Confidence
+	}
+	if len(block.Candidates) < 2 {
+		block.Candidates = nil
+	}
+
+	texts := make([]string, 0, len(line.Words))
+	for _, pw := range line.Words {
+		poly, err := parsePagePoints(pw.Coords.Points, w, h)
+		if err != nil {
+			return TextBlock{}, err
+		}
+		word := Word{BoundingBox: polygonBounds(poly), Confidence: UnknownConfidence}
+		if len(pw.TextEquiv) > 0 {
+			word.Text, word.Confidence = pw.TextEquiv[0].Unicode, parsePageConf(pw.TextEquiv[0].Conf)
+		}
+		block.Words = append(block.Words, word)
+		texts = append(texts, word.Text)
+	}
+	if len(line.TextEquiv) == 0 {
+		// 只有单词级文本时由单词拼出行文本
+		block.Text = strings.Join(texts, " ")
+	}
+	return block, nil
+}
+
+// parsePagePoints 解析 "x1,y1 x2,y2 ..." 格式的坐标并归一化。
+func parsePagePoints(s string, w, h float64) ([]Point, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, errors.New("sysocr: decode PAGE: missing Coords points")
+	}
+	poly := make([]Point, len(fields))
+	for i, f := range fields {
+		xs, ys, ok := strings.Cut(f, ",")
+		x, errX := strconv.ParseFloat(xs, 64)
+		y, errY := strconv.ParseFloat(ys, 64)
+		if !ok || errX != nil || errY != nil {
+			return nil, fmt.Errorf("sysocr: decode PAGE: invalid point %q", f)
+		}
+		poly[i] = Point{X: x / w, Y: y / h}
+	}
+	return poly, nil
+}
+
+// polygonBounds 返回多边形的外接矩形。
+func polygonBounds(poly []Point) BoundingBox {
+	minX, minY := poly[0].X, poly[0].Y
+	maxX, maxY := minX, minY
+	for _, p := range poly[1:] {
+		minX, maxX = min(minX, p.X), max(maxX, p.X)
+		minY, maxY = min(minY, p.Y), max(maxY, p.Y)
+	}
+	return BoundingBox{X: minX, Y: minY, Width: maxX - minX, Height: maxY - minY}
+}
+
+func parsePageConf(s string) float64 {
+	c, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return UnknownConfidence
+	}
+	return c
+}
@@ -0,0 +1,80 @@
+package sysocr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPAGERoundTrip(t *testing.T) {
+	want := roundTripResult()
+	// PAGE 还保留候选结果、四边形和倾斜角度
+	want.Blocks[0].Candidates = []Candidate{{Text: "Hello world", Confidence: 0.93}, {Text: "Hello wor1d", Confidence: 0.4}}
+	want.Blocks[2].Quad = Quad{{0.1, 0.22}, {0.6, 0.2}, {0.6, 0.24}, {0.1, 0.26}}
+	want.Blocks[2].BoundingBox = BoundingBox{0.1, 0.2, 0.5, 0.06}
+	want.TextAngle = 1.5
+
+	var buf bytes.Buffer
+	if err := want.WritePAGE(&buf, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "<Unicode>word</Unicode>") {
+		t.Fatalf("lines without word data were split into approximated words:\n%s", buf.String())
+	}
+	got, err := ReadPAGE(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareBlocks(t, got.Blocks, want.Blocks)
+	if c := got.Blocks[0].Candidates; len(c) != 2 || c[1].Text != "Hello wor1d" || !near(c[1].Confidence, 0.4) {
+		t.Errorf("candidates = %+v", c)
+	}
+	if got.ImageWidth != 1000 || got.ImageHeight != 500 || got.TextAngle != 1.5 {
+		t.Errorf("page = %dx%d angle %v", got.ImageWidth, got.ImageHeight, got.TextAngle)
+	}
+}
+
+func TestReadPAGE(t *testing.T) {
+	// 嵌套区域、多边形坐标和只有单词级文本的行
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<PcGts xmlns="http://schema.primaresearch.org/PAGE/gts/pagecontent/2019-07-15">
+ <Page imageFilename="scan.png" imageWidth="200" imageHeight="100">
+  <TextRegion id="r1">
+   <Coords points="0,0 200,0 200,100 0,100"/>
+   <TextRegion id="r1a">
+    <Coords points="0,0 200,0 200,50 0,50"/>
+    <TextLine id="l1">
+     <Coords points="10,10 110,10 110,20 60,25 10,20"/>
+     <Word id="w1"><Coords points="10,10 50,10 50,20 10,20"/><TextEquiv conf="0.9"><Unicode>only</Unicode></TextEquiv></Word>
+     <Word id="w2"><Coords points="60,10 110,10 110,20 60,20"/><TextEquiv><Unicode>words</Unicode></TextEquiv></Word>
+    </TextLine>
+   </TextRegion>
+  </TextRegion>
+ </Page>
+</PcGts>`
+	got, err := ReadPAGE(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TextBlock{{
+		Text: "only words", BoundingBox: BoundingBox{0.05, 0.1, 0.5, 0.15}, Confidence: UnknownConfidence,
+		Quad: BoundingBox{0.05, 0.1, 0.5, 0.15}.Quad(),
+		Words: []Word{
+			{Text: "only", BoundingBox: BoundingBox{0.05, 0.1, 0.2, 0.1}, Confidence: 0.9},
+			{Text: "words", BoundingBox: BoundingBox{0.3, 0.1, 0.25, 0.1}, Confidence: UnknownConfidence},
+		},
+	}}
+	compareBlocks(t, got.Blocks, want)
+}
+
+func TestReadPAGEInvalid(t *testing.T) {
+	for name, doc := range map[string]string{
+		"no size":   `<PcGts><Page><TextRegion/></Page></PcGts>`,
+		"bad point": `<PcGts><Page imageWidth="10" imageHeight="10"><TextRegion><TextLine><Coords points="1;2"/></TextLine></TextRegion></Page></PcGts>`,
+		"no coords": `<PcGts><Page imageWidth="10" imageHeight="10"><TextRegion><TextLine/></TextRegion></Page></PcGts>`,
+	} {
+		if _, err := ReadPAGE(strings.NewReader(doc)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package sysocr
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// DefaultPDFDPI 是 PDFOptions.DPI 为 0 时使用的图片分辨率。
+const DefaultPDFDPI = 300
+
+// PDFOptions 配置 WritePDF。
+type PDFOptions struct {
+	// DPI 是图片的分辨率，决定 PDF 页面的物理尺寸，0 表示 DefaultPDFDPI。
+	DPI float64
+}
+
+// pdfImage 是 PDF 中的图片 XObject。
+type pdfImage struct {
+	width, height int
+	colorSpace    string
+	bits          int
+	filter        string
+	decode        string // 可选的 Decode 数组
+	data          []byte
+}
+
+// pdfFont 为文本层分配字符编码：每个用到的字符一个 CID（从 1 开始），通过 ToUnicode 映射回 Unicode，
+// 通过 CIDToGIDMap 映射到 glyphlessFont 的空白字形。
+type pdfFont struct {
+	cids  map[rune]uint16
+	runes []rune
+}
+
+// WritePDF 生成可搜索的 PDF：以 imageData 作为页面图像，并按各行的位置叠加不可见的文本层，
+// 使 PDF 阅读器中的文本可以选择、复制和搜索。r 应为识别 imageData 得到的结果。
+//
+// JPEG 图片以 DCTDecode 原样嵌入，PNG 和 GIF 解码后以 Flate 压缩嵌入，其他格式返回 ErrUnsupportedFormat。
+// Result.Orientation 不为 0 时先按 EXIF 方向校正图片，使其与坐标一致。
+// 文本使用 Identity-H 编码的 Type0 字体（嵌入只有空白字形的 TrueType 字体）和渲染模式 3（不可见）；
+// 水平的行按单词定位，倾斜的行沿 TextBlock.Quad 的基线整体旋转。
+func (r *Result) WritePDF(w io.Writer, imageData []byte, opts PDFOptions) error {
+	info, err := DetectImage(imageData)
+	if err != nil {
+		return err
+	}
+	if r.Orientation != 0 {
+		imageData, info, _, err = normalizeOrientation(imageData, info)
+		if err != nil {
+			return err
+		}
+	}
+	img, err := newPDFImage(imageData, info)
+	if err != nil {
+		return err
+	}
+
+	dpi := opts.DPI
+	if dpi <= 0 {
+		dpi = DefaultPDFDPI
+	}
+	pageWidth := float64(img.width) * 72 / dpi
+	pageHeight := float64(img.height) * 72 / dpi
+
+	font := &pdfFont{cids: make(map[rune]uint16)}
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "q\n%s 0 0 %s 0 0 cm\n/Im1 Do\nQ\n", pdfNumber(pageWidth), pdfNumber(pageHeight))
+	r.writePDFText(&content, font, pageWidth, pageHeight)
+	contentData, err := deflate(content.Bytes())
+	if err != nil {
+		return err
+	}
+
+	pw := &pdfWriter{w: bufio.NewWriter(w)}
+	pw.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	pw.object("<< /Type /Catalog /Pages 2 0 R >>")
+	pw.object("<< /Type /Pages /Kids [3 0 R] /Count 1 >>")
+	pw.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+		"/Resources << /XObject << /Im1 5 0 R >> /Font << /F1 6 0 R >> >> /Contents 4 0 R >>",
+		pdfNumber(pageWidth), pdfNumber(pageHeight)))
+	pw.stream("/Filter /FlateDecode", contentData)
+	imageDict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent %d /Filter /%s",
+		img.width, img.height, img.colorSpace, img.bits, img.filter)
+	if img.decode != "" {
+		imageDict += " /Decode " + img.decode
+	}
+	pw.stream(imageDict, img.data)
+	pw.object("<< /Type /Font /Subtype /Type0 /BaseFont /GlyphLessFont /Encoding /Identity-H " +
+		"/DescendantFonts [7 0 R] /ToUnicode 9 0 R >>")
+	pw.object("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /GlyphLessFont " +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> " +
+		"/FontDescriptor 8 0 R /DW 500 /CIDToGIDMap 10 0 R >>")
+	pw.object("<< /Type /FontDescriptor /FontName /GlyphLessFont /Flags 5 /FontBBox [0 0 500 1000] " +
+		"/ItalicAngle 0 /Ascent 1000 /Descent 0 /CapHeight 1000 /StemV 80 /FontFile2 11 0 R >>")
+	pw.stream("", font.toUnicode())
+	gidMap, err := deflate(font.cidToGID())
+	if err != nil {
+		return err
+	}
+	pw.stream("/Filter /FlateDecode", gidMap)
+	fontFile := glyphlessFont()
+	fontData, err := deflate(fontFile)
+	if err != nil {
+		return err
+	}
+	pw.stream(fmt.Sprintf("/Filter /FlateDecode /Length1 %d", len(fontFile)), fontData)
+	pw.object("<< /Producer (sysocr) >>")
+	pw.finish(1, 12)
+	return pw.flush()
+}
+
+// newPDFImage 准备嵌入 PDF 的图片数据。
+func newPDFImage(data []byte, info ImageInfo) (*pdfImage, error) {
+	switch info.Format {
+	case FormatJPEG:
+		return jpegPDFImage(data, info)
+	case FormatPNG, FormatGIF:
+	default:
+		return nil, fmt.Errorf("%w: PDF output does not support %s images", ErrUnsupportedFormat, info.Format)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	b := src.Bounds()
+	img := &pdfImage{width: b.Dx(), height: b.Dy(), bits: 8, filter: "FlateDecode"}
+	var raw []byte
+	if gray, ok := src.(*image.Gray); ok {
+		img.colorSpace = "DeviceGray"
+		raw = make([]byte, 0, img.width*img.height)
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			off := gray.PixOffset(b.Min.X, y)
+			raw = append(raw, gray.Pix[off:off+img.width]...)
+		}
+	} else {
+		// 透明像素合成到白色背景上
+		img.colorSpace = "DeviceRGB"
+		raw = make([]byte, 0, img.width*img.height*3)
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				c := color.NRGBAModel.Convert(src.At(x, y)).(color.NRGBA)
+				a := uint32(c.A)
+				blend := func(v uint8) byte { return byte((uint32(v)*a + 255*(255-a)) / 255) }
+				raw = append(raw, blend(c.R), blend(c.G), blend(c.B))
+			}
+		}
+	}
+	img.data, err = deflate(raw)
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// jpegPDFImage 以 DCTDecode 原样嵌入 JPEG，颜色空间由 SOF 中的分量数决定。
+func jpegPDFImage(data []byte, info ImageInfo) (*pdfImage, error) {
+	img := &pdfImage{width: info.Width, height: info.Height, filter: "DCTDecode", data: data}
+	adobe := false
+	var components int
+	err := walkJPEG(data, func(marker byte, segment []byte) (bool, error) {
+		if marker == 0xee && bytes.HasPrefix(segment, []byte("Adobe")) {
+			adobe = true
+		}
+		if marker < 0xc0 || marker > 0xcf || marker == 0xc4 || marker == 0xc8 || marker == 0xcc {
+			return false, nil
+		}
+		if len(segment) < 6 {
+			return false, truncated(FormatJPEG)
+		}
+		img.bits = int(segment[0])
+		img.height = int(binary.BigEndian.Uint16(segment[1:3]))
+		img.width = int(binary.BigEndian.Uint16(segment[3:5]))
+		components = int(segment[5])
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch components {
+	case 1:
+		img.colorSpace = "DeviceGray"
+	case 3:
+		img.colorSpace = "DeviceRGB"
+	case 4:
+		img.colorSpace = "DeviceCMYK"
+		if adobe {
+			// Adobe 应用程序写出的 CMYK JPEG 是反相存储的
+			img.decode = "[1 0 1 0 1 0 1 0]"
+		}
+	default:
+		return nil, fmt.Errorf("%w: JPEG with %d components", ErrUnsupportedFormat, components)
+	}
+	return img, nil
+}
+
+// writePDFText 写出不可见的文本层，坐标从归一化坐标转换为 PDF 坐标（原点在左下角，单位为点）。
+func (r *Result) writePDFText(b *bytes.Buffer, font *pdfFont, pageWidth, pageHeight float64) {
+	toPDF := func(p Point) (float64, float64) {
+		return p.X * pageWidth, (1 - p.Y) * pageHeight
+	}
+
+	b.WriteString("BT\n3 Tr\n")
+	for _, block := range r.Blocks {
+		q := block.Quad
+		if q.IsZero() {
+			q = block.BoundingBox.Quad()
+		}
+		x0, y0 := toPDF(q[3]) // 左下角，文本的基线起点
+		x1, y1 := toPDF(q[2])
+		xt, yt := toPDF(q[0])
+		if angle := math.Atan2(y1-y0, x1-x0); math.Abs(angle) > math.Pi/360 {
+			// 倾斜的行沿基线整体旋转
+			sin, cos := math.Sincos(angle)
+			font.show(b, strings.TrimSpace(block.Text), math.Hypot(xt-x0, yt-y0), math.Hypot(x1-x0, y1-y0),
+				[6]float64{cos, sin, -sin, cos, x0, y0})
+			continue
+		}
+
+		words := SplitWords(block)
+		for i, word := range words {
+			box := word.BoundingBox
+			text := word.Text
+			if i < len(words)-1 {
+				// 单词后补一个空格，便于阅读器提取文本时分词
+				text += " "
+			}
+			font.show(b, text, box.Height*pageHeight, box.Width*pageWidth,
+				[6]float64{1, 0, 0, 1, box.X * pageWidth, (1 - box.Y - box.Height) * pageHeight})
+		}
+	}
+	b.WriteString("ET\n")
+}
+
+// show 写出一段文本：字号为 size，水平缩放使除末尾空格外的文本宽度恰好为 width。
+func (f *pdfFont) show(b *bytes.Buffer, text string, size, width float64, tm [6]float64) {
+	glyphs := len([]rune(strings.TrimRightFunc(text, unicode.IsSpace)))
+	if glyphs == 0 || size <= 0 || width <= 0 {
+		return
+	}
+	// 每个字形宽 500/1000 个字号单位（参见 /DW 500）
+	scale := 100 * width / (float64(glyphs) * size / 2)
+	fmt.Fprintf(b, "/F1 %s Tf\n%s Tz\n", pdfNumber(size), pdfNumber(scale))
+	for i, v := range tm {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(pdfNumber(v))
+	}
+	b.WriteString(" Tm\n<")
+	for _, r := range text {
+		fmt.Fprintf(b, "%04X", f.cid(r))
+	}
+	b.WriteString("> Tj\n")
+}
+
+// cid 返回字符的 CID，必要时分配新的 CID。CID 用完时返回 0（.notdef）。
+func (f *pdfFont) cid(r rune) uint16 {
+	if c, ok := f.cids[r]; ok {
+		return c
+	}
+	if len(f.runes) >= math.MaxUint16 {
+		return 0
+	}
+	f.runes = append(f.runes, r)
+	c := uint16(len(f.runes))
+	f.cids[r] = c
+	return c
+}
+
+// cidToGID 返回 CIDToGIDMap：CID 0 对应 .notdef，其余已分配的 CID 都对应 glyphlessFont 的空白字形 1。
+func (f *pdfFont) cidToGID() []byte {
+	m := make([]byte, 2*(len(f.runes)+1))
+	for i := 3; i < len(m); i += 2 {
+		m[i] = 1
+	}
+	return m
+}
+
+// toUnicode 返回把 CID 映射回 Unicode（UTF-16BE）的 ToUnicode CMap。
+func (f *pdfFont) toUnicode() []byte {
+	var b bytes.Buffer
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	// 每个 bfchar 段最多 100 项
+	for start := 0; start < len(f.runes); start += 100 {
+		chunk := f.runes[start:min(start+100, len(f.runes))]
+		fmt.Fprintf(&b, "%d beginbfchar\n", len(chunk))
+		for i, r := range chunk {
+			fmt.Fprintf(&b, "<%04X> <", start+i+1)
+			for _, u := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&b, "%04X", u)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+	}
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.Bytes()
+}
+
+// pdfWriter 顺序写出 PDF 对象并记录交叉引用表所需的偏移量。
+type pdfWriter struct {
+	w       *bufio.Writer
+	n       int
+	offsets []int
+	err     error
+}
+
+func (p *pdfWriter) printf(format string, args ...any) {
+	if p.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(p.w, format, args...)
+	p.n += n
+	p.err = err
+}
+
+func (p *pdfWriter) write(data []byte) {
+	if p.err != nil {
+		return
+	}
+	n, err := p.w.Write(data)
+	p.n += n
+	p.err = err
+}
+
+// object 写出下一个编号的对象。
+func (p *pdfWriter) object(body string) {
+	p.offsets = append(p.offsets, p.n)
+	p.printf("%d 0 obj\n%s\nendobj\n", len(p.offsets), body)
+}
+
+// stream 写出下一个编号的流对象，dict 为除 /Length 之外的字典项。
+func (p *pdfWriter) stream(dict string, data []byte) {
+	p.offsets = append(p.offsets, p.n)
+	if dict != "" {
+		dict += " "
+	}
+	p.printf("%d 0 obj\n<< %s/Length %d >>\nstream\n", len(p.offsets), dict, len(data))
+	p.write(data)
+	p.printf("\nendstream\nendobj\n")
+}
+
+// finish 写出交叉引用表和文件尾。
+func (p *pdfWriter) finish(root, info int) {
+	xref := p.n
+	p.printf("xref\n0 %d\n0000000000 65535 f \n", len(p.offsets)+1)
+	for _, off := range p.offsets {
+		p.printf("%010d 00000 n \n", off)
+	}
+	p.printf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(p.offsets)+1, root, info, xref)
+}
+
+func (p *pdfWriter) flush() error {
+	if p.err != nil {
+		return p.err
+	}
+	return p.w.Flush()
+}
+
+// deflate 以 zlib 格式压缩数据（PDF 的 FlateDecode）。
+func deflate(data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	zw := zlib.NewWriter(&b)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// pdfNumber 以最多 3 位小数格式化数字。
+func pdfNumber(f float64) string {
+	return strconv.FormatFloat(math.Round(f*1000)/1000, 'f', -1, 64)
+}
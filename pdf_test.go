@@ -0,0 +1,347 @@
+package sysocr
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// parsePDF 按交叉引用表读取 PDF 中的对象，并检查每个偏移量都指向对应的 "N 0 obj"。
+func parsePDF(t *testing.T, data []byte) map[int][]byte {
+	t.Helper()
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(data)
+	if m == nil {
+		t.Fatalf("no startxref at the end of the file:\n%q", data[max(0, len(data)-100):])
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	table := string(data[xref:])
+	var count int
+	if _, err := fmt.Sscanf(table, "xref\n0 %d\n", &count); err != nil {
+		t.Fatalf("startxref %d does not point at the xref table: %v", xref, err)
+	}
+	entries := strings.Split(table, "\n")[2:]
+	if entries[0] != "0000000000 65535 f " {
+		t.Fatalf("first xref entry = %q", entries[0])
+	}
+	if !strings.Contains(table, fmt.Sprintf("trailer\n<< /Size %d ", count)) {
+		t.Fatalf("trailer /Size does not match the xref table:\n%s", table)
+	}
+
+	objects := make(map[int][]byte)
+	for i := 1; i < count; i++ {
+		var offset, gen int
+		var kind string
+		if _, err := fmt.Sscanf(entries[i], "%010d %05d %s", &offset, &gen, &kind); err != nil || kind != "n" {
+			t.Fatalf("xref entry %d = %q", i, entries[i])
+		}
+		header := fmt.Sprintf("%d 0 obj\n", i)
+		if !bytes.HasPrefix(data[offset:], []byte(header)) {
+			t.Fatalf("xref entry %d points at %q", i, data[offset:min(offset+20, len(data))])
+		}
+		body := data[offset+len(header):]
+		end := bytes.Index(body, []byte("\nendobj\n"))
+		if end < 0 {
+			t.Fatalf("object %d has no endobj", i)
+		}
+		objects[i] = body[:end]
+	}
+	return objects
+}
+
+// pdfStream 返回流对象的数据，按 /Length 截取并解压 FlateDecode。
+func pdfStream(t *testing.T, obj []byte) []byte {
+	t.Helper()
+	m := regexp.MustCompile(`/Length (\d+) >>\nstream\n`).FindSubmatchIndex(obj)
+	if m == nil {
+		t.Fatalf("not a stream: %q", obj[:min(len(obj), 100)])
+	}
+	n, _ := strconv.Atoi(string(obj[m[2]:m[3]]))
+	data := obj[m[1]:]
+	if len(data) != n+len("\nendstream") || !bytes.HasSuffix(data, []byte("\nendstream")) {
+		t.Fatalf("/Length %d does not match the stream data (%d bytes)", n, len(data)-len("\nendstream"))
+	}
+	data = data[:n]
+	if !bytes.Contains(obj[:m[0]], []byte("/FlateDecode")) {
+		return data
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inflated, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return inflated
+}
+
+// pdfText 用 ToUnicode CMap 解码内容流中每个 Tj 的文本。
+func pdfText(t *testing.T, content, cmap []byte) []string {
+	t.Helper()
+	unicode := make(map[string]string)
+	for _, m := range regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]+)>`).FindAllSubmatch(cmap, -1) {
+		b, _ := hex.DecodeString(string(m[2]))
+		u := make([]uint16, len(b)/2)
+		for i := range u {
+			u[i] = binary.BigEndian.Uint16(b[2*i:])
+		}
+		unicode[string(m[1])] = string(utf16.Decode(u))
+	}
+	var texts []string
+	for _, m := range regexp.MustCompile(`<([0-9A-F]*)> Tj`).FindAllSubmatch(content, -1) {
+		var text strings.Builder
+		for s := string(m[1]); s != ""; s = s[4:] {
+			r, ok := unicode[s[:4]]
+			if !ok {
+				t.Fatalf("CID %s is not in the ToUnicode CMap", s[:4])
+			}
+			text.WriteString(r)
+		}
+		texts = append(texts, text.String())
+	}
+	return texts
+}
+
+func pdfTestResult() *Result {
+	return &Result{
+		Blocks: []TextBlock{
+			{
+				Text: "Hello world", BoundingBox: BoundingBox{0.1, 0.1, 0.5, 0.1}, Confidence: 0.9,
+				Words: []Word{
+					{Text: "Hello", BoundingBox: BoundingBox{0.1, 0.1, 0.2, 0.1}},
+					{Text: "world", BoundingBox: BoundingBox{0.35, 0.1, 0.25, 0.1}},
+				},
+			},
+			// 非 BMP 字符在 CMap 中以代理对表示
+			{Text: "中文 😀", BoundingBox: BoundingBox{0.1, 0.3, 0.3, 0.1}, Confidence: 0.9},
+			// 倾斜约 5.7° 的行整体旋转
+			{
+				Text: "Tilted", BoundingBox: BoundingBox{0.1, 0.5, 0.5, 0.2}, Confidence: 0.9,
+				Quad: Quad{{0.1, 0.6}, {0.6, 0.5}, {0.6, 0.6}, {0.1, 0.7}},
+			},
+		},
+		ImageWidth:  600,
+		ImageHeight: 300,
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 600, 300))
+	// 透明像素合成到白色背景上：完全透明、不透明的黑色、半透明的红色
+	src.Set(0, 0, color.NRGBA{255, 0, 0, 0})
+	src.Set(1, 0, color.NRGBA{0, 0, 0, 255})
+	src.Set(2, 0, color.NRGBA{255, 0, 0, 128})
+	var imageData bytes.Buffer
+	if err := png.Encode(&imageData, src); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := pdfTestResult().WritePDF(&buf, imageData.Bytes(), PDFOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-1.4\n")) {
+		t.Fatalf("header = %q", buf.Bytes()[:10])
+	}
+	objects := parsePDF(t, buf.Bytes())
+	if len(objects) != 12 {
+		t.Fatalf("got %d objects, want 12", len(objects))
+	}
+
+	// 300 DPI 下 600×300 像素为 144×72 点
+	if page := string(objects[3]); !strings.Contains(page, "/MediaBox [0 0 144 72]") {
+		t.Errorf("page = %s", page)
+	}
+	img := objects[5]
+	if !bytes.Contains(img, []byte("/Width 600 /Height 300 /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode")) {
+		t.Errorf("image = %s", img[:bytes.Index(img, []byte("stream"))])
+	}
+	pixels := pdfStream(t, img)
+	if len(pixels) != 600*300*3 || !bytes.Equal(pixels[:9], []byte{255, 255, 255, 0, 0, 0, 255, 127, 127}) {
+		t.Errorf("image data: %d bytes, first pixels %v", len(pixels), pixels[:9])
+	}
+
+	content := pdfStream(t, objects[4])
+	got := pdfText(t, content, pdfStream(t, objects[9]))
+	want := []string{"Hello ", "world", "中文 ", "😀", "Tilted"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("text layer = %q, want %q", got, want)
+	}
+	if !bytes.Contains(content, []byte("/Im1 Do")) || !bytes.Contains(content, []byte("3 Tr")) {
+		t.Errorf("content stream does not draw the image or hide the text:\n%s", content)
+	}
+	// 第一个单词：左下角 (0.1×144, 0.8×72)，字号为行高 7.2 点
+	if !bytes.Contains(content, []byte("/F1 7.2 Tf\n")) || !bytes.Contains(content, []byte("1 0 0 1 14.4 57.6 Tm\n")) {
+		t.Errorf("first word is not positioned at its box:\n%s", content)
+	}
+	// 倾斜的行：基线从 (14.4, 21.6) 到 (86.4, 28.8)，逆时针旋转约 5.7°
+	if !regexp.MustCompile(`0\.995 0\.1 -0\.1 0\.995 14\.4 21\.6 Tm\n`).Match(content) {
+		t.Errorf("tilted line is not rotated along its baseline:\n%s", content)
+	}
+}
+
+func TestWritePDFFont(t *testing.T) {
+	var buf bytes.Buffer
+	if err := pdfTestResult().WritePDF(&buf, testPNG(t, 600, 300), PDFOptions{DPI: 72}); err != nil {
+		t.Fatal(err)
+	}
+	objects := parsePDF(t, buf.Bytes())
+	if !bytes.Contains(objects[7], []byte("/CIDToGIDMap 10 0 R")) || !bytes.Contains(objects[8], []byte("/FontFile2 11 0 R")) {
+		t.Fatalf("font does not reference CIDToGIDMap and FontFile2:\n%s\n%s", objects[7], objects[8])
+	}
+
+	// 用到 14 个不同的字符：CID 0 对应 .notdef，CID 1-14 对应空白字形 1
+	gidMap := pdfStream(t, objects[10])
+	if len(gidMap) != 2*15 || binary.BigEndian.Uint16(gidMap) != 0 {
+		t.Fatalf("CIDToGIDMap = %v", gidMap)
+	}
+	for i := 2; i < len(gidMap); i += 2 {
+		if binary.BigEndian.Uint16(gidMap[i:]) != 1 {
+			t.Fatalf("CIDToGIDMap = %v", gidMap)
+		}
+	}
+
+	font := pdfStream(t, objects[11])
+	if !bytes.Equal(font, glyphlessFont()) || !bytes.Contains(objects[11], []byte(fmt.Sprintf("/Length1 %d ", len(font)))) {
+		t.Fatalf("FontFile2 does not contain the glyphless font:\n%s", objects[11][:80])
+	}
+}
+
+func TestGlyphlessFont(t *testing.T) {
+	font := glyphlessFont()
+	if binary.BigEndian.Uint32(font) != 0x00010000 {
+		t.Fatalf("sfnt version = %x", font[:4])
+	}
+	if sum := ttfChecksum(font); sum != 0xb1b0afba {
+		t.Errorf("font checksum = %#x, want 0xb1b0afba", sum)
+	}
+	n := int(binary.BigEndian.Uint16(font[4:]))
+	tables := make(map[string][]byte)
+	prev := ""
+	for i := range n {
+		entry := font[12+16*i:]
+		tag := string(entry[:4])
+		sum, offset, length := binary.BigEndian.Uint32(entry[4:]), binary.BigEndian.Uint32(entry[8:]), binary.BigEndian.Uint32(entry[12:])
+		if tag <= prev || offset%4 != 0 || int(offset+length) > len(font) {
+			t.Fatalf("table %q at %d+%d", tag, offset, length)
+		}
+		prev = tag
+		data := font[offset : offset+length]
+		if tag != "head" && ttfChecksum(data) != sum {
+			t.Errorf("%s checksum = %#x, want %#x", tag, ttfChecksum(data), sum)
+		}
+		tables[tag] = data
+	}
+	for _, tag := range []string{"cmap", "glyf", "head", "hhea", "hmtx", "loca", "maxp", "name", "post"} {
+		if _, ok := tables[tag]; !ok {
+			t.Errorf("missing %s table", tag)
+		}
+	}
+	// 2 个字形，宽度都是 500/1000 em，与 /DW 500 一致
+	be := binary.BigEndian
+	if len(tables["head"]) != 54 || be.Uint16(tables["head"][18:]) != 1000 {
+		t.Errorf("head = %x", tables["head"])
+	}
+	if be.Uint16(tables["maxp"][4:]) != 2 || be.Uint16(tables["hhea"][34:]) != 2 || len(tables["loca"]) != 6 {
+		t.Errorf("glyph count: maxp %x, hhea %x, loca %x", tables["maxp"], tables["hhea"], tables["loca"])
+	}
+	if !bytes.Equal(tables["hmtx"], []byte{0x01, 0xf4, 0, 0, 0x01, 0xf4, 0, 0}) {
+		t.Errorf("hmtx = %x", tables["hmtx"])
+	}
+}
+
+// cmykJPEG 返回只有文件头的 4 分量 JPEG，adobe 为 true 时带有 Adobe APP14 段。
+func cmykJPEG(adobe bool) []byte {
+	data := []byte{0xff, 0xd8}
+	if adobe {
+		data = append(data, 0xff, 0xee, 0, 14)
+		data = append(data, "Adobe\x00\x64\x00\x00\x00\x00\x02"...)
+	}
+	data = append(data, 0xff, 0xc0, 0, 20, 8, 0, 2, 0, 3, 4)
+	for id := byte(1); id <= 4; id++ {
+		data = append(data, id, 0x11, 0)
+	}
+	return append(data, 0xff, 0xd9)
+}
+
+func TestJPEGPDFImage(t *testing.T) {
+	encode := func(img image.Image) []byte {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, nil); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	for _, tt := range []struct {
+		name       string
+		data       []byte
+		colorSpace string
+		decode     string
+	}{
+		{"gray", encode(image.NewGray(image.Rect(0, 0, 3, 2))), "DeviceGray", ""},
+		{"rgb", encode(image.NewRGBA(image.Rect(0, 0, 3, 2))), "DeviceRGB", ""},
+		{"cmyk", cmykJPEG(false), "DeviceCMYK", ""},
+		// Adobe 写出的 CMYK 是反相存储的
+		{"adobe cmyk", cmykJPEG(true), "DeviceCMYK", "[1 0 1 0 1 0 1 0]"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := DetectImage(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, err := newPDFImage(tt.data, info)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if img.colorSpace != tt.colorSpace || img.decode != tt.decode || img.filter != "DCTDecode" ||
+				img.bits != 8 || img.width != 3 || img.height != 2 || !bytes.Equal(img.data, tt.data) {
+				t.Fatalf("image = %s %s %s %d bits %dx%d", img.colorSpace, img.decode, img.filter, img.bits, img.width, img.height)
+			}
+		})
+	}
+
+	// 2 分量的 JPEG 没有对应的颜色空间
+	twoComponents := cmykJPEG(false)
+	twoComponents[11] = 2
+	if _, err := jpegPDFImage(twoComponents, ImageInfo{}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("2 components: err = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestWritePDFOrientation(t *testing.T) {
+	// EXIF 方向为顺时针 90° 的 16×8 JPEG，识别结果基于 8×16 的显示方向
+	jpg := withExif(testJPEG(t), exifTIFF(binary.LittleEndian, 0x0112, 3, uint16(OrientationRotate90)))
+	r := &Result{Orientation: OrientationRotate90, ImageWidth: 8, ImageHeight: 16}
+	var buf bytes.Buffer
+	if err := r.WritePDF(&buf, jpg, PDFOptions{DPI: 72}); err != nil {
+		t.Fatal(err)
+	}
+	objects := parsePDF(t, buf.Bytes())
+	if !bytes.Contains(objects[3], []byte("/MediaBox [0 0 8 16]")) || !bytes.Contains(objects[5], []byte("/Width 8 /Height 16")) {
+		t.Fatalf("page %s, image %s", objects[3], objects[5][:80])
+	}
+}
+
+func TestWritePDFUnsupported(t *testing.T) {
+	bmp := append([]byte("BM"), make([]byte, 24)...)
+	binary.LittleEndian.PutUint32(bmp[18:], 4)
+	binary.LittleEndian.PutUint32(bmp[22:], 4)
+	if err := pdfTestResult().WritePDF(io.Discard, bmp, PDFOptions{}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("BMP: err = %v, want ErrUnsupportedFormat", err)
+	}
+	if err := pdfTestResult().WritePDF(io.Discard, []byte("not an image"), PDFOptions{}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("text: err = %v, want ErrUnsupportedFormat", err)
+	}
+}
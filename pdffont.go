@@ -0,0 +1,91 @@
+package sysocr
+
+import (
+	"encoding/binary"
+	"sort"
+	"sync"
+)
+
+// glyphlessFont 返回嵌入 PDF 文本层的 TrueType 字体（与 tesseract 的 GlyphLessFont 相同的做法）：
+// 只有 .notdef 和一个空白字形，两者宽 500/1000 em，文本层的所有 CID 都映射到字形 1。
+// 嵌入字体后阅读器不会提示缺少字体，也不会用替代字体绘制文本。
+var glyphlessFont = sync.OnceValue(func() []byte {
+	be := binary.BigEndian
+	u16 := func(b []byte, v ...uint16) []byte {
+		for _, x := range v {
+			b = be.AppendUint16(b, x)
+		}
+		return b
+	}
+	u32 := func(b []byte, v ...uint32) []byte {
+		for _, x := range v {
+			b = be.AppendUint32(b, x)
+		}
+		return b
+	}
+
+	head := u32(nil, 0x00010000, 0x00010000, 0, 0x5f0f3cf5) // checkSumAdjustment 最后填写
+	head = u16(head, 0x000b, 1000)                          // flags、unitsPerEm
+	head = append(head, make([]byte, 16)...)                // created、modified
+	head = u16(head, 0, 0, 500, 1000, 0, 3, 2, 0, 0)        // xMin…yMax、macStyle、lowestRecPPEM、方向、短 loca、glyphDataFormat
+	hhea := u32(nil, 0x00010000)
+	hhea = u16(hhea, 1000, 0, 0, 500, 0, 0, 500, 1, 0, 0, 0, 0, 0, 0, 0, 2)
+	maxp := u32(nil, 0x00010000)
+	maxp = u16(maxp, 2, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 0)
+	// cmap 只有格式 4 的结束段，字形通过 CIDToGIDMap 选择
+	cmap := u16(nil, 0, 1, 3, 1)
+	cmap = u32(cmap, 12)
+	cmap = u16(cmap, 4, 24, 0, 2, 2, 0, 0, 0xffff, 0, 0xffff, 1, 0)
+	post := u32(nil, 0x00030000, 0, 0, 1, 0, 0, 0, 0)
+
+	tables := map[string][]byte{
+		"cmap": cmap,
+		"glyf": nil, // 两个字形都没有轮廓
+		"head": head,
+		"hhea": hhea,
+		"hmtx": u16(nil, 500, 0, 500, 0),
+		"loca": u16(nil, 0, 0, 0),
+		"maxp": maxp,
+		"name": u16(nil, 0, 0, 6),
+		"post": post,
+	}
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	// 表目录：9 个表时 searchRange 为 8×16，entrySelector 为 3
+	n := uint16(len(tags))
+	font := u32(nil, 0x00010000)
+	font = u16(font, n, 128, 3, n*16-128)
+	offset := len(font) + len(tags)*16
+	var data []byte
+	var headOffset int
+	for _, tag := range tags {
+		t := tables[tag]
+		if tag == "head" {
+			headOffset = offset + len(data)
+		}
+		font = append(font, tag...)
+		font = u32(font, ttfChecksum(t), uint32(offset+len(data)), uint32(len(t)))
+		data = append(data, t...)
+		data = append(data, make([]byte, (4-len(t)%4)%4)...)
+	}
+	font = append(font, data...)
+
+	// head.checkSumAdjustment 使整个文件的校验和为 0xB1B0AFBA
+	be.PutUint32(font[headOffset+8:], 0xb1b0afba-ttfChecksum(font))
+	return font
+})
+
+// ttfChecksum 返回 TrueType 表的校验和：按 32 位大端整数求和，末尾不足 4 字节补零。
+func ttfChecksum(b []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(b); i += 4 {
+		var word [4]byte
+		copy(word[:], b[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
@@ -0,0 +1,146 @@
+package sysocr
+
+import (
+	"sort"
+)
+
+// ReadingOrder 指定文本块的阅读顺序。
+type ReadingOrder int
+
+const (
+	// ReadingOrderEngine 保持引擎返回的顺序（默认）。
+	ReadingOrderEngine ReadingOrder = iota
+	// ReadingOrderLeftToRight 识别分栏，栏从左到右、栏内从上到下阅读，适用于大多数横排文档。
+	ReadingOrderLeftToRight
+	// ReadingOrderRightToLeft 识别分栏，栏从右到左、栏内从上到下阅读，适用于阿拉伯文、希伯来文。
+	ReadingOrderRightToLeft
+	// ReadingOrderVerticalCJK 用于中日韩竖排文本：列从右到左、列内从上到下阅读。
+	ReadingOrderVerticalCJK
+)
+
+// orderBox 是像素空间中的文本块，用于计算阅读顺序。
+type orderBox struct {
+	index int
+	pixelRect
+}
+
+// SortReadingOrder 按 order 返回重新排列后的 blocks，不修改传入的切片。
+//
+// 排序使用递归 XY-cut：每一步在水平和垂直投影中找到最宽的空白，沿该空白把文本块切成两部分，
+// 直到无法再切分。跨栏的标题会先被水平切开，随后栏间的空白把正文切成独立的栏，
+// 因此两栏论文、报纸等版面不会逐行交错。
+// imageWidth、imageHeight 用于将归一化坐标还原为像素比例，未知时传 0。
+func SortReadingOrder(blocks []TextBlock, order ReadingOrder, imageWidth, imageHeight int) []TextBlock {
+	sorted := make([]TextBlock, len(blocks))
+	if order == ReadingOrderEngine || len(blocks) < 2 {
+		copy(sorted, blocks)
+		return sorted
+	}
+
+	sx, sy := pixelScale(imageWidth, imageHeight)
+	boxes := make([]orderBox, len(blocks))
+	for i, b := range blocks {
+		boxes[i] = orderBox{index: i, pixelRect: b.BoundingBox.pixelRect(sx, sy)}
+	}
+
+	indices := make([]int, 0, len(blocks))
+	xyCut(boxes, order, &indices)
+	for i, idx := range indices {
+		sorted[i] = blocks[idx]
+	}
+	return sorted
+}
+
+// xyCut 递归切分 boxes，并按阅读顺序把下标追加到 dst。
+func xyCut(boxes []orderBox, order ReadingOrder, dst *[]int) {
+	if len(boxes) == 1 {
+		*dst = append(*dst, boxes[0].index)
+		return
+	}
+
+	posX, gapX := widestGap(boxes, func(b orderBox) (float64, float64) { return b.x0, b.x1 })
+	posY, gapY := widestGap(boxes, func(b orderBox) (float64, float64) { return b.y0, b.y1 })
+	if gapX > 0 || gapY > 0 {
+		var first, second []orderBox
+		if gapX > gapY {
+			first, second = partition(boxes, func(b orderBox) bool { return b.x1 <= posX })
+			if order != ReadingOrderLeftToRight {
+				first, second = second, first
+			}
+		} else {
+			first, second = partition(boxes, func(b orderBox) bool { return b.y1 <= posY })
+		}
+		// 坐标含 NaN 等异常值时切分可能不成立，此时不再递归，避免无限递归
+		if len(first) > 0 && len(second) > 0 {
+			xyCut(first, order, dst)
+			xyCut(second, order, dst)
+			return
+		}
+	}
+	sortUncut(boxes, order)
+	for _, b := range boxes {
+		*dst = append(*dst, b.index)
+	}
+}
+
+// widestGap 返回投影到某一坐标轴后最宽空白的起点和宽度，没有空白时宽度为 0。
+// span 返回文本块在该轴上的起止坐标。
+func widestGap(boxes []orderBox, span func(orderBox) (float64, float64)) (pos, gap float64) {
+	spans := make([][2]float64, len(boxes))
+	for i, b := range boxes {
+		spans[i][0], spans[i][1] = span(b)
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	end := spans[0][1]
+	for _, s := range spans[1:] {
+		if s[0]-end > gap {
+			pos, gap = end, s[0]-end
+		}
+		end = max(end, s[1])
+	}
+	return pos, gap
+}
+
+// partition 将 boxes 分为满足 first 的部分和其余部分，两部分保持原有的相对顺序。
+func partition(boxes []orderBox, first func(orderBox) bool) (a, b []orderBox) {
+	for _, box := range boxes {
+		if first(box) {
+			a = append(a, box)
+		} else {
+			b = append(b, box)
+		}
+	}
+	return a, b
+}
+
+// sortUncut 排序投影相互重叠、无法再切分的文本块。
+// 横排时先按行（垂直中心接近的视为同一行）再按水平方向；竖排时先按列再按垂直方向。
+func sortUncut(boxes []orderBox, order ReadingOrder) {
+	if order == ReadingOrderVerticalCJK {
+		sort.SliceStable(boxes, func(i, j int) bool {
+			a, b := boxes[i], boxes[j]
+			if sameBand(a.x0, a.x1, b.x0, b.x1) {
+				return a.y0 < b.y0
+			}
+			return a.x0+a.x1 > b.x0+b.x1
+		})
+		return
+	}
+	sort.SliceStable(boxes, func(i, j int) bool {
+		a, b := boxes[i], boxes[j]
+		if sameBand(a.y0, a.y1, b.y0, b.y1) {
+			if order == ReadingOrderRightToLeft {
+				return a.x1 > b.x1
+			}
+			return a.x0 < b.x0
+		}
+		return a.y0+a.y1 < b.y0+b.y1
+	})
+}
+
+// sameBand 判断两个区间的中心是否落在对方的范围内，即属于同一行（或同一列）。
+func sameBand(a0, a1, b0, b1 float64) bool {
+	ca, cb := (a0+a1)/2, (b0+b1)/2
+	return (ca >= b0 && ca <= b1) || (cb >= a0 && cb <= a1)
+}
@@ -0,0 +1,101 @@
+package sysocr
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func blockTexts(blocks []TextBlock) string {
+	texts := make([]string, len(blocks))
+	for i, b := range blocks {
+		texts[i] = b.Text
+	}
+	return strings.Join(texts, " ")
+}
+
+func TestSortReadingOrder(t *testing.T) {
+	for _, tt := range []struct {
+		order ReadingOrder
+		want  string
+	}{
+		{ReadingOrderEngine, "Title spanning L1 R1 L2 R2 L3 R3"},
+		{ReadingOrderLeftToRight, "Title spanning L1 L2 L3 R1 R2 R3"},
+		{ReadingOrderRightToLeft, "Title spanning R1 R2 R3 L1 L2 L3"},
+	} {
+		blocks := twoColumnPage()
+		got := SortReadingOrder(blocks, tt.order, 1000, 1000)
+		if blockTexts(got) != tt.want {
+			t.Errorf("order %d: %q, want %q", tt.order, blockTexts(got), tt.want)
+		}
+		if blockTexts(blocks) != "Title spanning L1 R1 L2 R2 L3 R3" {
+			t.Fatal("SortReadingOrder modified its argument")
+		}
+	}
+}
+
+func TestSortReadingOrderVerticalCJK(t *testing.T) {
+	// 两列竖排文本，每列内上下两段；上下之间的空白比列间距宽，先被切开，各段内右列先读
+	blocks := []TextBlock{
+		line("左上", 100, 100, 30, 300), line("右上", 200, 100, 30, 300),
+		line("左下", 100, 500, 30, 300), line("右下", 200, 500, 30, 300),
+	}
+	if got := blockTexts(SortReadingOrder(blocks, ReadingOrderVerticalCJK, 1000, 1000)); got != "右上 左上 右下 左下" {
+		t.Fatalf("vertical order = %q", got)
+	}
+	touching := []TextBlock{line("左", 100, 100, 30, 300), line("右", 125, 100, 30, 300)}
+	if got := blockTexts(SortReadingOrder(touching, ReadingOrderVerticalCJK, 1000, 1000)); got != "右 左" {
+		t.Fatalf("overlapping vertical columns = %q, want right to left", got)
+	}
+}
+
+func TestSortReadingOrderRow(t *testing.T) {
+	// 同一行中相互重叠、无法切分的文本按水平位置排序
+	blocks := []TextBlock{line("b", 140, 100, 60, 20), line("a", 100, 105, 60, 20)}
+	if got := blockTexts(SortReadingOrder(blocks, ReadingOrderLeftToRight, 1000, 1000)); got != "a b" {
+		t.Fatalf("left to right = %q", got)
+	}
+	if got := blockTexts(SortReadingOrder(blocks, ReadingOrderRightToLeft, 1000, 1000)); got != "b a" {
+		t.Fatalf("right to left = %q", got)
+	}
+}
+
+func TestSortReadingOrderDegenerate(t *testing.T) {
+	nan := math.NaN()
+	for name, blocks := range map[string][]TextBlock{
+		"negative width": {
+			{Text: "a", BoundingBox: BoundingBox{0.5, 0.1, -0.4, 0.03}},
+			{Text: "b", BoundingBox: BoundingBox{0.2, 0.1, 0.1, 0.03}},
+			{Text: "c", BoundingBox: BoundingBox{0.7, 0.1, 0.1, 0.03}},
+		},
+		"negative height": {
+			{Text: "a", BoundingBox: BoundingBox{0.1, 0.5, 0.1, -0.4}},
+			{Text: "b", BoundingBox: BoundingBox{0.1, 0.2, 0.1, 0.03}},
+			{Text: "c", BoundingBox: BoundingBox{0.1, 0.7, 0.1, 0.03}},
+		},
+		"NaN": {
+			{Text: "a", BoundingBox: BoundingBox{nan, nan, nan, nan}},
+			{Text: "b", BoundingBox: BoundingBox{0.2, 0.1, 0.1, 0.03}},
+			{Text: "c", BoundingBox: BoundingBox{0.7, 0.1, 0.1, 0.03}},
+			{Text: "d", BoundingBox: BoundingBox{0.2, 0.5, nan, 0.03}},
+		},
+		"zero size": {
+			{Text: "a", BoundingBox: BoundingBox{0.2, 0.2, 0, 0}},
+			{Text: "b", BoundingBox: BoundingBox{0.2, 0.2, 0, 0}},
+		},
+	} {
+		for _, order := range []ReadingOrder{ReadingOrderLeftToRight, ReadingOrderRightToLeft, ReadingOrderVerticalCJK} {
+			got := SortReadingOrder(blocks, order, 100, 100)
+			if len(got) != len(blocks) {
+				t.Errorf("%s, order %d: got %d blocks, want %d", name, order, len(got), len(blocks))
+			}
+			seen := make(map[string]bool)
+			for _, b := range got {
+				seen[b.Text] = true
+			}
+			if len(seen) != len(blocks) {
+				t.Errorf("%s, order %d: %q is not a permutation", name, order, blockTexts(got))
+			}
+		}
+	}
+}
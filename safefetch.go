@@ -0,0 +1,126 @@
+package sysocr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+)
+
+// ErrForbiddenURL 表示安全获取模式下 URL 指向了被禁止的地址。
+// 具体信息见 *ForbiddenURLError，可以用 errors.Is(err, ErrForbiddenURL) 判断。
+var ErrForbiddenURL = errors.New("sysocr: forbidden URL")
+
+// ForbiddenURLError 描述被安全获取模式拒绝的目标地址。
+type ForbiddenURLError struct {
+	Host string     // 请求的主机名
+	Addr netip.Addr // 主机解析得到的被禁止地址
+}
+
+func (e *ForbiddenURLError) Error() string {
+	if _, err := netip.ParseAddr(e.Host); err == nil {
+		return "sysocr: forbidden URL: address " + e.Host + " is not allowed"
+	}
+	return "sysocr: forbidden URL: host " + e.Host + " resolves to forbidden address " + e.Addr.String()
+}
+
+// Unwrap 使 errors.Is(err, ErrForbiddenURL) 成立。
+func (e *ForbiddenURLError) Unwrap() error {
+	return ErrForbiddenURL
+}
+
+// reservedPrefixes 是 netip.Addr 的分类方法之外，安全模式默认拒绝的网段。
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "本网络"
+	netip.MustParsePrefix("100.64.0.0/10"),  // 运营商级 NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF 协议分配
+	netip.MustParsePrefix("198.18.0.0/15"),  // 基准测试
+	netip.MustParsePrefix("240.0.0.0/4"),    // 保留地址及广播
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64，可映射到任意 IPv4 内网地址
+	netip.MustParsePrefix("64:ff9b:1::/48"), // 本地 NAT64
+	netip.MustParsePrefix("2002::/16"),      // 6to4，可嵌入 IPv4 内网地址
+}
+
+// addrForbidden 判断安全模式下是否拒绝连接 addr。
+// AllowCIDRs 优先于 DenyCIDRs 和默认规则。
+func addrForbidden(addr netip.Addr, fetch FetchOptions) bool {
+	addr = addr.Unmap()
+	for _, p := range fetch.AllowCIDRs {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	for _, p := range fetch.DenyCIDRs {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+	for _, p := range reservedPrefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// safeClient 返回一个在建立连接时校验目标地址的 HTTP 客户端，以及用完后需要调用的清理函数。
+//
+// 地址检查发生在拨号阶段：主机名解析后逐一检查所有地址，并直接连接检查过的地址，
+// 因此重定向和 DNS 重绑定都无法绕过。代理会绕过这一检查，所以安全模式下不使用代理。
+func safeClient(fetch FetchOptions) (*http.Client, func(), error) {
+	base := fetch.Client
+	if base == nil {
+		base = http.DefaultClient
+	}
+
+	var transport *http.Transport
+	switch t := base.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return nil, nil, errors.New("sysocr: safe fetch requires the client transport to be *http.Transport")
+	}
+
+	dialer := &net.Dialer{}
+	transport.Proxy = nil
+	// 设置了 DialTLSContext 或已弃用的 DialTLS 时 HTTPS 连接不经过 DialContext
+	transport.DialTLSContext = nil
+	transport.DialTLS = nil
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			if addrForbidden(addr, fetch) {
+				return nil, &ForbiddenURLError{Host: host, Addr: addr.Unmap()}
+			}
+		}
+
+		var lastErr error
+		for _, addr := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		return nil, lastErr
+	}
+
+	client := *base
+	client.Transport = transport
+	return &client, transport.CloseIdleConnections, nil
+}
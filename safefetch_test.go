@@ -0,0 +1,156 @@
+package sysocr
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestAddrForbidden(t *testing.T) {
+	prefixes := func(s ...string) []netip.Prefix {
+		var ps []netip.Prefix
+		for _, p := range s {
+			ps = append(ps, netip.MustParsePrefix(p))
+		}
+		return ps
+	}
+	for _, tt := range []struct {
+		addr      string
+		fetch     FetchOptions
+		forbidden bool
+	}{
+		{"8.8.8.8", FetchOptions{}, false},
+		{"2001:4860:4860::8888", FetchOptions{}, false},
+		{"127.0.0.1", FetchOptions{}, true},
+		{"127.0.0.2", FetchOptions{}, true},
+		{"::1", FetchOptions{}, true},
+		{"::ffff:127.0.0.1", FetchOptions{}, true},
+		{"10.1.2.3", FetchOptions{}, true},
+		{"172.16.0.1", FetchOptions{}, true},
+		{"192.168.1.1", FetchOptions{}, true},
+		{"fd00::1", FetchOptions{}, true},
+		{"169.254.169.254", FetchOptions{}, true}, // 云服务的元数据地址
+		{"fe80::1", FetchOptions{}, true},
+		{"0.0.0.0", FetchOptions{}, true},
+		{"::", FetchOptions{}, true},
+		{"224.0.0.1", FetchOptions{}, true},
+		{"100.64.0.1", FetchOptions{}, true},
+		{"255.255.255.255", FetchOptions{}, true},
+		{"64:ff9b::a00:1", FetchOptions{}, true}, // NAT64 映射的 10.0.0.1
+		{"2002:a00:1::", FetchOptions{}, true},   // 6to4 嵌入的 10.0.0.1
+		{"127.0.0.1", FetchOptions{AllowCIDRs: prefixes("127.0.0.0/8")}, false},
+		{"::ffff:127.0.0.1", FetchOptions{AllowCIDRs: prefixes("127.0.0.1/32")}, false},
+		{"127.0.0.2", FetchOptions{AllowCIDRs: prefixes("127.0.0.1/32")}, true},
+		{"8.8.8.8", FetchOptions{DenyCIDRs: prefixes("8.8.0.0/16")}, true},
+		{"8.8.8.8", FetchOptions{AllowCIDRs: prefixes("8.8.8.8/32"), DenyCIDRs: prefixes("8.8.0.0/16")}, false},
+	} {
+		if got := addrForbidden(netip.MustParseAddr(tt.addr), tt.fetch); got != tt.forbidden {
+			t.Errorf("addrForbidden(%s, allow %v, deny %v) = %v, want %v",
+				tt.addr, tt.fetch.AllowCIDRs, tt.fetch.DenyCIDRs, got, tt.forbidden)
+		}
+	}
+}
+
+// withHost 将 URL 的主机替换为 host，端口不变。
+func withHost(t *testing.T, rawURL, host string) string {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Host = net.JoinHostPort(host, u.Port())
+	return u.String()
+}
+
+func TestFetchURLSafe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			// 重定向到另一个回环地址，只有 127.0.0.1 被允许
+			http.Redirect(w, r, withHost(t, "http://"+r.Host, "127.0.0.2")+"/", http.StatusFound)
+			return
+		}
+		w.Write([]byte("data"))
+	}))
+	defer srv.Close()
+	allowServer := []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32"), netip.MustParsePrefix("::1/128")}
+
+	for _, tt := range []struct {
+		name      string
+		url       string
+		fetch     FetchOptions
+		wantAddr  string // 被拒绝的地址，为空表示请求成功
+		wantLocal bool   // 地址检查发生在拨号阶段而不是请求前
+	}{
+		{"unsafe", srv.URL, FetchOptions{}, "", false},
+		{"literal IP", srv.URL, FetchOptions{Safe: true}, "127.0.0.1", false},
+		{"mapped IPv6", withHost(t, srv.URL, "::ffff:127.0.0.1"), FetchOptions{Safe: true}, "127.0.0.1", false},
+		{"localhost", withHost(t, srv.URL, "localhost"), FetchOptions{Safe: true}, "", true},
+		{"allowed", srv.URL, FetchOptions{Safe: true, AllowCIDRs: allowServer}, "", false},
+		{"redirect", srv.URL + "/redirect", FetchOptions{Safe: true, AllowCIDRs: allowServer}, "127.0.0.2", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := fetchURL(context.Background(), tt.url, tt.fetch)
+			if tt.wantAddr == "" && !tt.wantLocal {
+				if err != nil || string(data) != "data" {
+					t.Fatalf("fetchURL = %q, %v", data, err)
+				}
+				return
+			}
+			var forbidden *ForbiddenURLError
+			if !errors.Is(err, ErrForbiddenURL) || !errors.As(err, &forbidden) {
+				t.Fatalf("err = %v, want ErrForbiddenURL", err)
+			}
+			if tt.wantLocal {
+				// localhost 可能解析为 127.0.0.1 或 ::1
+				if forbidden.Host != "localhost" || !forbidden.Addr.IsLoopback() {
+					t.Fatalf("err = %+v, want a loopback address for localhost", forbidden)
+				}
+				return
+			}
+			if forbidden.Addr.String() != tt.wantAddr {
+				t.Fatalf("forbidden address = %v, want %s", forbidden.Addr, tt.wantAddr)
+			}
+		})
+	}
+}
+
+func TestFetchURLSafeTransport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("data"))
+	}))
+	defer srv.Close()
+	target := withHost(t, srv.URL, "localhost")
+
+	// 调用方的传输层自行建立 TLS 连接时，安全模式仍然要检查地址
+	dialTLS := func(network, addr string) (net.Conn, error) {
+		return tls.Dial(network, addr, &tls.Config{InsecureSkipVerify: true})
+	}
+	for name, transport := range map[string]*http.Transport{
+		"DialTLS": {DialTLS: dialTLS},
+		"DialTLSContext": {DialTLSContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialTLS(network, addr)
+		}},
+	} {
+		client := &http.Client{Transport: transport}
+		if _, err := fetchURL(context.Background(), target, FetchOptions{Client: client}); err != nil {
+			t.Fatalf("%s without Safe: %v", name, err)
+		}
+		_, err := fetchURL(context.Background(), target, FetchOptions{Client: client, Safe: true})
+		if !errors.Is(err, ErrForbiddenURL) {
+			t.Errorf("%s: err = %v, want ErrForbiddenURL", name, err)
+		}
+	}
+
+	client := &http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)}
+	_, err := fetchURL(context.Background(), "http://example.com/", FetchOptions{Client: client, Safe: true})
+	if err == nil || !strings.Contains(err.Error(), "*http.Transport") {
+		t.Errorf("custom RoundTripper: err = %v", err)
+	}
+}
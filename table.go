@@ -0,0 +1,212 @@
+package sysocr
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+)
+
+// tableCellGap 是同一行内拆分单元格的最小水平间距（以行高为单位）。
+const tableCellGap = 1.0
+
+// Table 是从文本块中提取的表格，Rows 中每一行的单元格数量相同，没有文本的单元格 Text 为空。
+type Table struct {
+	BoundingBox BoundingBox `json:"bounding_box"`
+	Rows        [][]Cell    `json:"rows"`
+}
+
+// Cell 是表格中的一个单元格。
+type Cell struct {
+	Row         int         `json:"row"`
+	Col         int         `json:"col"`
+	Text        string      `json:"text"`
+	BoundingBox BoundingBox `json:"bounding_box"` // 单元格中文本的边界框，空单元格为零值
+}
+
+// tableSegment 是一段属于同一单元格的文本，坐标为像素比例。
+type tableSegment struct {
+	text string
+	box  BoundingBox
+	pixelRect
+}
+
+// ExtractTable 根据边界框的对齐关系把 blocks 聚类为行和列，返回表格。
+// 少于两行或两列时返回 nil。
+//
+// 引擎把一整行表格识别为一行文本时，按单词之间较大的空白拆分单元格（需要单词信息）。
+// 列由至少包含两个单元格的行在水平方向上的投影确定，因此跨列的标题行不会把各列合并；
+// 每个单元格归入与其重叠最多的列，同一单元格内的多段文本以空格连接。
+// imageWidth、imageHeight 用于将归一化坐标还原为像素比例，未知时传 0。
+func ExtractTable(blocks []TextBlock, imageWidth, imageHeight int) *Table {
+	sx, sy := pixelScale(imageWidth, imageHeight)
+	var segments []tableSegment
+	for _, b := range blocks {
+		segments = append(segments, splitCells(b, sx, sy)...)
+	}
+	if len(segments) == 0 {
+		return nil
+	}
+
+	// 按垂直方向的重叠把单元格分行
+	sort.SliceStable(segments, func(i, j int) bool {
+		return segments[i].y0+segments[i].y1 < segments[j].y0+segments[j].y1
+	})
+	var rows [][]tableSegment
+	var rowY0, rowY1 float64
+	for _, s := range segments {
+		if len(rows) == 0 || !sameBand(rowY0, rowY1, s.y0, s.y1) {
+			rows = append(rows, nil)
+			rowY0, rowY1 = s.y0, s.y1
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], s)
+		rowY0, rowY1 = min(rowY0, s.y0), max(rowY1, s.y1)
+	}
+
+	columns := tableColumns(rows)
+	if len(rows) < 2 || len(columns) < 2 {
+		return nil
+	}
+
+	t := &Table{Rows: make([][]Cell, len(rows))}
+	for r, row := range rows {
+		sort.SliceStable(row, func(i, j int) bool { return row[i].x0 < row[j].x0 })
+		t.Rows[r] = make([]Cell, len(columns))
+		for c := range t.Rows[r] {
+			t.Rows[r][c] = Cell{Row: r, Col: c}
+		}
+		for _, s := range row {
+			cell := &t.Rows[r][bestColumn(columns, s)]
+			if cell.Text != "" {
+				cell.Text += " "
+			}
+			cell.Text += s.text
+			cell.BoundingBox = cell.BoundingBox.Union(s.box)
+			t.BoundingBox = t.BoundingBox.Union(s.box)
+		}
+	}
+	return t
+}
+
+// splitCells 按单词之间较大的空白把一行拆分为多个单元格。
+func splitCells(b TextBlock, sx, sy float64) []tableSegment {
+	newSegment := func(text string, box BoundingBox) tableSegment {
+		return tableSegment{text: text, box: box, pixelRect: box.pixelRect(sx, sy)}
+	}
+	text := strings.TrimSpace(b.Text)
+	if text == "" {
+		return nil
+	}
+	if len(b.Words) < 2 {
+		return []tableSegment{newSegment(text, b.BoundingBox)}
+	}
+
+	gap := tableCellGap * b.BoundingBox.Height * sy
+	var segments []tableSegment
+	var words []string
+	var box BoundingBox
+	for i, w := range b.Words {
+		if i > 0 {
+			prev := b.Words[i-1].BoundingBox
+			if (w.BoundingBox.X-prev.X-prev.Width)*sx > gap {
+				segments = append(segments, newSegment(strings.Join(words, " "), box))
+				words, box = nil, BoundingBox{}
+			}
+		}
+		words = append(words, w.Text)
+		box = box.Union(w.BoundingBox)
+	}
+	return append(segments, newSegment(strings.Join(words, " "), box))
+}
+
+// tableColumns 合并多单元格行在水平方向上的投影，返回各列的区间。
+// 没有多单元格的行时返回 nil。
+func tableColumns(rows [][]tableSegment) [][2]float64 {
+	var spans [][2]float64
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		for _, s := range row {
+			spans = append(spans, [2]float64{s.x0, s.x1})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	var columns [][2]float64
+	for _, s := range spans {
+		if n := len(columns); n > 0 && s[0] < columns[n-1][1] {
+			columns[n-1][1] = max(columns[n-1][1], s[1])
+			continue
+		}
+		columns = append(columns, s)
+	}
+	return columns
+}
+
+// bestColumn 返回与 s 水平重叠最多的列，都不重叠时返回中心距离最近的列。
+func bestColumn(columns [][2]float64, s tableSegment) int {
+	best, bestOverlap, bestDist := 0, 0.0, -1.0
+	for i, c := range columns {
+		if overlap := min(c[1], s.x1) - max(c[0], s.x0); overlap > bestOverlap {
+			best, bestOverlap = i, overlap
+		}
+		if bestOverlap > 0 {
+			continue
+		}
+		dist := (c[0] + c[1] - s.x0 - s.x1) / 2
+		if dist < 0 {
+			dist = -dist
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return best
+}
+
+// Strings 返回各单元格的文本。
+func (t *Table) Strings() [][]string {
+	records := make([][]string, len(t.Rows))
+	for i, row := range t.Rows {
+		records[i] = make([]string, len(row))
+		for j, cell := range row {
+			records[i][j] = cell.Text
+		}
+	}
+	return records
+}
+
+// WriteCSV 以 CSV 格式写出表格。
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(t.Strings()); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteMarkdown 以 Markdown 表格格式写出表格，第一行作为表头。
+func (t *Table) WriteMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	for i, row := range t.Strings() {
+		sb.WriteString("|")
+		for _, text := range row {
+			text = strings.ReplaceAll(text, "|", `\|`)
+			text = strings.Join(strings.Fields(text), " ")
+			sb.WriteString(" " + text + " |")
+		}
+		sb.WriteString("\n")
+		if i == 0 {
+			sb.WriteString("|" + strings.Repeat(" --- |", len(row)) + "\n")
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteJSON 以 JSON 格式写出表格，包括每个单元格的位置。
+func (t *Table) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(t)
+}
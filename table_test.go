@@ -0,0 +1,170 @@
+package sysocr
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// wordsLine 返回一个由单词组成的行，words 为 (文本, x, 宽度)，坐标为 1000×1000 页面中的像素。
+func wordsLine(y, h float64, words ...any) TextBlock {
+	var b TextBlock
+	for i := 0; i < len(words); i += 3 {
+		w := line(words[i].(string), words[i+1].(float64), y, words[i+2].(float64), h)
+		b.Words = append(b.Words, Word{Text: w.Text, BoundingBox: w.BoundingBox})
+		b.BoundingBox = b.BoundingBox.Union(w.BoundingBox)
+		if b.Text != "" {
+			b.Text += " "
+		}
+		b.Text += w.Text
+	}
+	return b
+}
+
+func TestExtractTable(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		blocks []TextBlock
+		want   [][]string
+	}{
+		{
+			name: "cells as blocks",
+			blocks: []TextBlock{
+				line("Name", 100, 100, 80, 20), line("Qty", 400, 100, 40, 20), line("Price", 600, 100, 60, 20),
+				line("Apple", 100, 130, 70, 20), line("3", 420, 130, 10, 20), line("1.50", 600, 130, 50, 20),
+			},
+			want: [][]string{{"Name", "Qty", "Price"}, {"Apple", "3", "1.50"}},
+		},
+		{
+			name: "spanning header row",
+			blocks: []TextBlock{
+				line("Quarterly fruit report", 100, 50, 580, 30),
+				line("Name", 100, 100, 80, 20), line("Qty", 400, 100, 40, 20), line("Price", 600, 100, 60, 20),
+				line("Apple", 100, 130, 70, 20), line("3", 420, 130, 10, 20), line("1.50", 600, 130, 50, 20),
+			},
+			want: [][]string{
+				{"Quarterly fruit report", "", ""},
+				{"Name", "Qty", "Price"},
+				{"Apple", "3", "1.50"},
+			},
+		},
+		{
+			name: "empty cells",
+			blocks: []TextBlock{
+				line("Name", 100, 100, 80, 20), line("Qty", 400, 100, 40, 20), line("Price", 600, 100, 60, 20),
+				line("Banana", 100, 130, 90, 20), line("0.25", 600, 130, 50, 20),
+				line("12", 420, 160, 20, 20),
+			},
+			want: [][]string{{"Name", "Qty", "Price"}, {"Banana", "", "0.25"}, {"", "12", ""}},
+		},
+		{
+			name: "line split by word gaps",
+			blocks: []TextBlock{
+				wordsLine(100, 20, "Name", 100.0, 80.0, "Qty", 400.0, 40.0, "Price", 600.0, 60.0),
+				// "Green apple" 单词间距小于行高，保持在同一单元格中
+				wordsLine(130, 20, "Green", 100.0, 70.0, "apple", 180.0, 70.0, "3", 420.0, 10.0, "1.50", 600.0, 50.0),
+			},
+			want: [][]string{{"Name", "Qty", "Price"}, {"Green apple", "3", "1.50"}},
+		},
+		{
+			name: "one row",
+			blocks: []TextBlock{
+				line("Name", 100, 100, 80, 20), line("Qty", 400, 100, 40, 20),
+			},
+		},
+		{
+			name: "one column",
+			blocks: []TextBlock{
+				line("Apple", 100, 100, 80, 20), line("Banana", 100, 130, 80, 20), line("Cherry", 100, 160, 80, 20),
+			},
+		},
+		{
+			name:   "blank",
+			blocks: []TextBlock{line("  ", 100, 100, 80, 20)},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			table := ExtractTable(tt.blocks, 1000, 1000)
+			if tt.want == nil {
+				if table != nil {
+					t.Fatalf("ExtractTable = %q, want nil", table.Strings())
+				}
+				return
+			}
+			if table == nil {
+				t.Fatal("ExtractTable = nil")
+			}
+			if got := table.Strings(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ExtractTable = %q, want %q", got, tt.want)
+			}
+			for r, row := range table.Rows {
+				for c, cell := range row {
+					if cell.Row != r || cell.Col != c {
+						t.Errorf("cell (%d,%d) has position (%d,%d)", r, c, cell.Row, cell.Col)
+					}
+					if cell.Text == "" && !cell.BoundingBox.Empty() {
+						t.Errorf("empty cell (%d,%d) has box %+v", r, c, cell.BoundingBox)
+					}
+				}
+			}
+		})
+	}
+}
+
+// escapeTable 是包含需要转义的字符的表格。
+func escapeTable() *Table {
+	texts := [][]string{
+		{"Name", "Note"},
+		{"a|b", "1,000"},
+		{`say "hi"`, "two\nlines"},
+	}
+	t := &Table{}
+	for r, row := range texts {
+		cells := make([]Cell, len(row))
+		for c, text := range row {
+			cells[c] = Cell{Row: r, Col: c, Text: text}
+		}
+		t.Rows = append(t.Rows, cells)
+	}
+	return t
+}
+
+func TestTableWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := escapeTable().WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "Name,Note\na|b,\"1,000\"\n\"say \"\"hi\"\"\",\"two\nlines\"\n"
+	if buf.String() != want {
+		t.Fatalf("WriteCSV =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestTableWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := escapeTable().WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "| Name | Note |\n" +
+		"| --- | --- |\n" +
+		"| a\\|b | 1,000 |\n" +
+		"| say \"hi\" | two lines |\n"
+	if buf.String() != want {
+		t.Fatalf("WriteMarkdown =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestTableWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := escapeTable().WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got Table
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Strings(), escapeTable().Strings()) || got.Rows[2][1].Row != 2 || got.Rows[2][1].Col != 1 {
+		t.Fatalf("WriteJSON round trip = %+v", got)
+	}
+}
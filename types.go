@@ -1,34 +1,159 @@
 package sysocr
 
+import (
+	"image"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/netip"
+)
+
 // BoundingBox 表示文本在图片中的位置。
 type BoundingBox struct {
-	X      float64
-	Y      float64
-	Width  float64
-	Height float64
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Point 表示图片中的一个点，坐标归一化到 0-1，原点在左上角。
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
 }
 
-// TextBlock 表示识别到的文本块及其位置信息。
+// Quad 表示文本的四边形区域，依次为左上、右上、右下、左下四个角（相对于文本方向）。
+// 对于倾斜的文本，Quad 比轴对齐的 BoundingBox 更精确。
+type Quad [4]Point
+
+// TextBlock 表示识别到的文本块（一行文本）及其位置信息。
 type TextBlock struct {
-	Text        string
-	BoundingBox BoundingBox
+	Text        string      `json:"text"`
+	BoundingBox BoundingBox `json:"bounding_box"`
+	Quad        Quad        `json:"quad"`            // 四个角点，引擎不提供时与 BoundingBox 相同
+	Words       []Word      `json:"words,omitempty"` // 行内的单词，引擎不提供单词信息时为空（参见 SplitWords）
+	Confidence  float64     `json:"confidence"`      // 置信度 0-1，未知时为 UnknownConfidence
+
+	// Candidates 是按置信度从高到低排列的候选结果，第一个与 Text 相同。
+	// 仅在 Options.MaxCandidates 大于 0 且引擎支持时填充。
+	Candidates []Candidate `json:"candidates,omitempty"`
+}
+
+// Candidate 表示一行文本的一个候选识别结果。
+type Candidate struct {
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"` // 置信度 0-1，未知时为 UnknownConfidence
+}
+
+// Word 表示行内的一个单词及其位置信息。
+type Word struct {
+	Text        string      `json:"text"`
+	BoundingBox BoundingBox `json:"bounding_box"`
+	Confidence  float64     `json:"confidence"` // 置信度 0-1，未知时为 UnknownConfidence
+}
+
+// UnknownConfidence 表示引擎没有提供置信度。
+const UnknownConfidence = -1.0
+
+// Page 是按版面分析组织的页面结构：区域 → 段落 → 行 → 单词。
+type Page struct {
+	Width   int      `json:"width"`  // 图片像素宽度
+	Height  int      `json:"height"` // 图片像素高度
+	Regions []Region `json:"regions"`
+}
+
+// Region 是页面中相互邻接的一块文本区域，如一栏正文、一个标题或一个侧栏。
+type Region struct {
+	BoundingBox BoundingBox `json:"bounding_box"`
+	Paragraphs  []Paragraph `json:"paragraphs"`
+}
+
+// Paragraph 是区域中的一个段落，行保持 Result.Blocks 中的顺序。
+type Paragraph struct {
+	BoundingBox BoundingBox `json:"bounding_box"`
+	Lines       []TextBlock `json:"lines"`
 }
 
 // Result 包含 OCR 识别结果。
 type Result struct {
-	Blocks []TextBlock
-	Text   string // 所有文本拼接
+	// SchemaVersion 是 JSON 表示的版本号，Recognize 返回的结果为 SchemaVersion1，
+	// 参见 ResultJSONSchema。
+	SchemaVersion int `json:"schema_version"`
+
+	Blocks []TextBlock `json:"blocks"`
+	Text   string      `json:"text"` // 所有文本拼接
+
+	// Page 是由 Blocks 分析得到的版面结构，与 Blocks 包含相同的行。
+	// 由 Recognize 填充，引擎不需要设置。JSON 中不包含该字段，UnmarshalResult 会重新分析得到。
+	Page *Page `json:"-"`
+
+	// TextAngle 是页面中文本相对水平方向的顺时针倾斜角度（度）。
+	// 引擎不提供时根据各行的 Quad 估算。
+	TextAngle float64 `json:"text_angle"`
+
+	// ImageWidth、ImageHeight 是识别时图片的像素尺寸（EXIF 方向校正之后），
+	// 可用 BoundingBox.Pixels 将归一化坐标转换为像素坐标。
+	ImageWidth  int `json:"image_width"`
+	ImageHeight int `json:"image_height"`
+
+	// Orientation 是识别前按 EXIF 方向标签对图片施加的变换，0 表示未做变换。
+	// 此时坐标基于校正后的图片（即用户看到的方向），可以用 Orientation.ToOriginal 映射回原文件。
+	Orientation Orientation `json:"orientation,omitempty"`
 }
 
-// Input 指定图片来源，三个字段只能设置其中一个。
+// Input 指定图片来源，FilePath、URL、Data、Reader、Image、Base64 只能设置其中一个。
+//
+// 设置 FS 时，FilePath 表示 FS 中的路径（斜杠分隔、不以 / 开头，参见 fs.ValidPath），
+// 可用于 embed.FS、zip.Reader 等文件系统。
+//
+// Reader 会被完整读入内存且不限制大小，来自不可信来源的数据流（如 HTTP 请求体）
+// 应先用 io.LimitReader 或 http.MaxBytesReader 包装。
 type Input struct {
-	FilePath string // 本地文件路径
-	URL      string // 远程 URL (http/https)
-	Data     []byte // 内存中的图片数据
+	FilePath string      // 本地文件路径，或 FS 中的路径
+	URL      string      // 远程 URL (http/https)，或 data URI（如 "data:image/png;base64,..."）
+	Data     []byte      // 内存中的图片数据
+	Reader   io.Reader   // 图片数据流，读取到 EOF 为止
+	Image    image.Image // 已解码的图片，检查 MaxImagePixels 后以无损格式编码一次
+	Base64   string      // base64 编码的图片数据，也接受 data URI
+	FS       fs.FS       // 可选：从该文件系统读取 FilePath
 }
 
 // Options 配置 OCR 识别参数。
 type Options struct {
 	Input     Input
 	Languages []string // 可选：语言提示（如 "zh-Hans", "en"）
+	Engine    string   // 可选：引擎名称，为空时使用默认引擎
+	Fetch     FetchOptions
+
+	// MaxImagePixels 限制图片的像素总数（宽×高），超出时返回 ErrImageTooLarge。
+	// 0 表示使用 DefaultMaxImagePixels。
+	MaxImagePixels int64
+
+	// MinConfidence 丢弃置信度低于该值的文本块（在拼接 Result.Text 之前），0 表示不过滤。
+	// 置信度未知的文本块始终保留；引擎不提供置信度（Capabilities.Confidence 为 false）时不过滤。
+	MinConfidence float64
+
+	// MaxCandidates 为每行最多返回的候选结果数量（TextBlock.Candidates），0 表示不返回。
+	MaxCandidates int
+
+	// ReadingOrder 指定 Blocks 和 Text 的排列顺序，默认保持引擎返回的顺序。
+	// 多栏文档可使用 ReadingOrderLeftToRight 等模式避免各栏逐行交错。
+	ReadingOrder ReadingOrder
+
+	// IgnoreOrientation 为 true 时不按 JPEG 的 EXIF 方向标签校正图片，坐标基于原文件的像素方向。
+	IgnoreOrientation bool
+}
+
+// FetchOptions 配置 Input.URL 的 HTTP 获取行为，零值等价于使用 http.DefaultClient 且不做限制。
+type FetchOptions struct {
+	Client       *http.Client // 可选：HTTP 客户端，默认为 http.DefaultClient
+	Header       http.Header  // 可选：附加的请求头（如 Authorization、User-Agent）
+	MaxBytes     int64        // 可选：响应体大小上限（字节），0 表示不限制
+	ContentTypes []string     // 可选：允许的 Content-Type（如 "image/png"、"image/*"），为空表示不检查
+
+	// Safe 启用安全获取模式：拒绝连接回环、私有、链路本地等内网地址，
+	// 重定向和 DNS 重绑定后的地址同样会被检查。用于获取最终用户提供的 URL。
+	Safe       bool
+	AllowCIDRs []netip.Prefix // 可选：安全模式下始终允许的网段，优先于其他规则
+	DenyCIDRs  []netip.Prefix // 可选：安全模式下额外拒绝的网段
 }
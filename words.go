@@ -0,0 +1,98 @@
+package sysocr
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SplitWords 按空白切分行文本，并按字符宽度比例切分行的边界框，近似得到单词的位置。
+//
+// 用于不提供单词信息的引擎（Capabilities.Words 为 false）。如果 b.Words 已有数据则直接返回。
+// 全角字符（如中日韩文字）按两个半角字符的宽度计算；不含空白的行返回单个单词。
+// 近似得到的单词没有置信度（UnknownConfidence）。
+func SplitWords(b TextBlock) []Word {
+	if len(b.Words) > 0 {
+		return b.Words
+	}
+
+	total := textWidth(b.Text)
+	if total == 0 {
+		return nil
+	}
+
+	var words []Word
+	box := b.BoundingBox
+	offset := 0
+	rest := b.Text
+	for rest != "" {
+		// 跳过前导空白
+		trimmed := strings.TrimLeftFunc(rest, unicode.IsSpace)
+		offset += textWidth(rest[:len(rest)-len(trimmed)])
+		rest = trimmed
+		if rest == "" {
+			break
+		}
+
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			end = len(rest)
+		}
+		word := rest[:end]
+		width := textWidth(word)
+
+		words = append(words, Word{
+			Text: word,
+			BoundingBox: BoundingBox{
+				X:      box.X + box.Width*float64(offset)/float64(total),
+				Y:      box.Y,
+				Width:  box.Width * float64(width) / float64(total),
+				Height: box.Height,
+			},
+			Confidence: UnknownConfidence,
+		})
+		offset += width
+		rest = rest[end:]
+	}
+	return words
+}
+
+// textWidth 返回文本的显示宽度（半角字符为 1，全角字符为 2）。
+func textWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// runeWidth 返回字符的显示宽度：组合字符和控制字符为 0，东亚全角字符为 2，其余为 1。
+func runeWidth(r rune) int {
+	switch {
+	case r == utf8.RuneError:
+		return 1
+	case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || unicode.IsControl(r):
+		return 0
+	case isWide(r):
+		return 2
+	}
+	return 1
+}
+
+// isWide 判断字符是否为东亚全角字符。
+func isWide(r rune) bool {
+	return (r >= 0x1100 && r <= 0x115f) || // 谚文字母
+		(r >= 0x2e80 && r <= 0x303e) || // 中日韩部首、标点
+		(r >= 0x3041 && r <= 0x33ff) || // 假名、注音及中日韩兼容字符
+		(r >= 0x3400 && r <= 0x4dbf) || // 中日韩统一表意文字扩展 A
+		(r >= 0x4e00 && r <= 0x9fff) || // 中日韩统一表意文字
+		(r >= 0xa000 && r <= 0xa4cf) || // 彝文
+		(r >= 0xac00 && r <= 0xd7a3) || // 谚文音节
+		(r >= 0xf900 && r <= 0xfaff) || // 中日韩兼容表意文字
+		(r >= 0xfe30 && r <= 0xfe4f) || // 中日韩兼容形式
+		(r >= 0xff00 && r <= 0xff60) || // 全角 ASCII
+		(r >= 0xffe0 && r <= 0xffe6) || // 全角符号
+		(r >= 0x1f300 && r <= 0x1f64f) || // 表情符号
+		(r >= 0x1f900 && r <= 0x1f9ff) ||
+		(r >= 0x20000 && r <= 0x3fffd) // 中日韩统一表意文字扩展 B 及之后
+}
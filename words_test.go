@@ -0,0 +1,42 @@
+package sysocr
+
+import "testing"
+
+func TestSplitWords(t *testing.T) {
+	b := TextBlock{Text: " ab  中文 c", BoundingBox: BoundingBox{X: 0, Y: 0.5, Width: 1, Height: 0.1}, Confidence: 0.9}
+	words := SplitWords(b)
+	want := []struct {
+		text string
+		x, w float64
+	}{
+		// 总宽度 1+2+2+4+1+1 = 11
+		{"ab", 1.0 / 11, 2.0 / 11},
+		{"中文", 5.0 / 11, 4.0 / 11},
+		{"c", 10.0 / 11, 1.0 / 11},
+	}
+	if len(words) != len(want) {
+		t.Fatalf("SplitWords = %+v", words)
+	}
+	for i, w := range want {
+		got := words[i]
+		if got.Text != w.text || !near(got.BoundingBox.X, w.x) || !near(got.BoundingBox.Width, w.w) {
+			t.Errorf("word %d = %+v, want %q at %v width %v", i, got, w.text, w.x, w.w)
+		}
+		if got.BoundingBox.Y != 0.5 || got.BoundingBox.Height != 0.1 {
+			t.Errorf("word %d box = %+v, want the line's Y and height", i, got.BoundingBox)
+		}
+		if got.Confidence != UnknownConfidence {
+			t.Errorf("word %d confidence = %v, want UnknownConfidence", i, got.Confidence)
+		}
+	}
+}
+
+func TestSplitWordsExisting(t *testing.T) {
+	b := TextBlock{Text: "a b", Words: []Word{{Text: "ab", Confidence: 0.7}}}
+	if words := SplitWords(b); len(words) != 1 || words[0].Confidence != 0.7 {
+		t.Fatalf("SplitWords = %+v, want the block's own words", words)
+	}
+	if words := SplitWords(TextBlock{Text: "  "}); words != nil {
+		t.Fatalf("SplitWords(blank) = %+v, want nil", words)
+	}
+}